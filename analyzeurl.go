@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Runs the single story given by -analyze-url through the analyzer and
+// prints the insight like headless mode. Without -analyze-title the title
+// is taken from the page.
+func runAnalyzeURL(ctx context.Context, cfg Config) error {
+	title := cfg.AnalyzeTitle
+	if title == "" {
+		var err error
+		if title, err = fetchPageTitle(ctx, cfg.AnalyzeURL); err != nil {
+			return fmt.Errorf("fetching title (set -analyze-title to skip): %w", err)
+		}
+	}
+
+	insight, err := analyzeStory(ctx, cfg, Story{Title: title, URL: cfg.AnalyzeURL})
+	if err != nil {
+		return err
+	}
+	headlessHandlers(cfg).insight(cfg, insight, nil)
+	return nil
+}
+
+// Matches an HTML page's title element
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// Fetches the page at url and returns the text of its <title>. Pages are
+// read up to -max-body-bytes, since the title sits near the top anyway.
+func fetchPageTitle(ctx context.Context, url string) (string, error) {
+	resp, err := httpGet(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", &responseError{
+			StatusCode: resp.StatusCode,
+			msg:        fmt.Sprintf("unexpected status %d from %s", resp.StatusCode, url),
+		}
+	}
+
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return "", err
+	}
+	match := titlePattern.FindSubmatch(page)
+	if match == nil {
+		return "", fmt.Errorf("%s has no <title>", url)
+	}
+	// Pages in legacy encodings can yield invalid UTF-8; titles decoded from
+	// JSON are already valid, since encoding/json replaces bad bytes
+	title := strings.ToValidUTF8(string(match[1]), "\uFFFD")
+	title = strings.Join(strings.Fields(html.UnescapeString(title)), " ")
+	if title == "" {
+		return "", fmt.Errorf("%s has an empty <title>", url)
+	}
+	return title, nil
+}
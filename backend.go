@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// How often -on-backend-down=retry probes Ollama while it's down
+const backendProbeInterval = 15 * time.Second
+
+// Set while the startup check found Ollama or the model unavailable.
+// Stories are shown as headlines; with -on-backend-down=retry they're also
+// queued, like during a pause, and analyzed once a probe succeeds.
+var backendDown atomic.Bool
+
+// The -on-backend-down mode, set at startup for the status bar
+var backendMode string
+
+// Confirms the Ollama server at baseURL answers and has the analysis model
+func checkBackend(ctx context.Context, baseURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	models, err := listModels(ctx, baseURL)
+	if err != nil {
+		return err
+	}
+	for _, m := range models {
+		// Untagged names refer to :latest, but any tag of the model will do
+		if m.Name == analysisModel || strings.HasPrefix(m.Name, analysisModel+":") {
+			return nil
+		}
+	}
+	return fmt.Errorf("model %s is not available on %s; run: ollama pull %s", analysisModel, baseURL, analysisModel)
+}
+
+// Runs checkBackend on each server, passing if any of them is usable
+func checkBackends(ctx context.Context, urls []string) error {
+	var first error
+	for _, u := range urls {
+		err := checkBackend(ctx, u)
+		if err == nil {
+			return nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Re-runs the startup check until it passes or ctx is done, then lets
+// analysis start. The queued headlines are picked up by the next cycle.
+func probeBackend(ctx context.Context, urls []string) {
+	ticker := time.NewTicker(backendProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if err := checkBackends(ctx, urls); err != nil {
+			slog.Debug("Ollama still unavailable", "err", err)
+			continue
+		}
+		slog.Info("Ollama is up, starting analysis", "queued", pausedCount())
+		backendDown.Store(false)
+		return
+	}
+}
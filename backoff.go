@@ -0,0 +1,36 @@
+package main
+
+import (
+	"time"
+)
+
+// Poll loop backoff: after backoffAfter consecutive failed cycles the wait
+// doubles on each further failure, up to maxBackoff or -interval if longer
+const (
+	backoffAfter = 3
+	maxBackoff   = 5 * time.Minute
+)
+
+// Tracks consecutive failures and yields the wait before the next attempt
+type backoff struct {
+	base     time.Duration
+	max      time.Duration
+	after    int // Failures tolerated before the wait starts growing
+	failures int
+}
+
+// Records the outcome of an attempt and returns how long to wait before the
+// next one. A success resets the wait to base.
+func (b *backoff) next(ok bool) time.Duration {
+	if ok {
+		b.failures = 0
+		return b.base
+	}
+
+	b.failures++
+	d := b.base
+	for i := b.after; i < b.failures && d < b.max; i++ {
+		d *= 2
+	}
+	return max(min(d, b.max), b.base)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		max      time.Duration
+		outcomes []bool
+		want     []time.Duration
+	}{
+		{
+			"grows after the tolerated failures, up to the cap",
+			5 * time.Second,
+			maxBackoff,
+			[]bool{false, false, false, false, false, true},
+			[]time.Duration{5 * time.Second, 5 * time.Second, 5 * time.Second, 10 * time.Second, 20 * time.Second, 5 * time.Second},
+		},
+		{
+			"capped at maxBackoff",
+			time.Minute,
+			maxBackoff,
+			[]bool{false, false, false, false, false, false, false},
+			[]time.Duration{time.Minute, time.Minute, time.Minute, 2 * time.Minute, 4 * time.Minute, maxBackoff, maxBackoff},
+		},
+		{
+			"never below an -interval above the cap",
+			10 * time.Minute,
+			maxBackoff,
+			[]bool{false, false, false, false, true},
+			[]time.Duration{10 * time.Minute, 10 * time.Minute, 10 * time.Minute, 10 * time.Minute, 10 * time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := backoff{base: tt.interval, max: tt.max, after: backoffAfter}
+			for i, ok := range tt.outcomes {
+				if got := b.next(ok); got != tt.want[i] {
+					t.Errorf("attempt %d (ok %v): next() = %v, want %v", i+1, ok, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+)
+
+var errNoBrowser = errors.New("no way to open a browser (install xdg-utils)")
+
+// Opens url in the default browser by shelling out to the platform's opener.
+// The opener is started without waiting, so a slow browser doesn't stall the UI.
+func openInBrowser(url string) error {
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		args = []string{"open", url}
+	case "windows":
+		args = []string{"rundll32", "url.dll,FileProtocolHandler", url}
+	default:
+		args = []string{"xdg-open", url}
+	}
+
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return errNoBrowser
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
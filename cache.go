@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A short-lived in-memory cache of HN item lookups keyed by item ID
+type itemCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration // Default lifetime; 0 disables caching
+	entries map[int]cachedStory
+}
+
+type cachedStory struct {
+	story   Story
+	expires time.Time
+}
+
+// Cache shared by all item lookups; its TTL is set from -item-cache-ttl
+var storyCache = &itemCache{entries: make(map[int]cachedStory)}
+
+// Returns the cached story for id if it hasn't expired
+func (c *itemCache) get(id int) (Story, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[id]
+	if !ok || time.Now().After(cached.expires) {
+		return Story{}, false
+	}
+	return cached.story, true
+}
+
+// Caches a story fetched with the given response headers. A Cache-Control
+// max-age overrides the default TTL and no-store skips caching entirely.
+func (c *itemCache) put(id int, story Story, header http.Header) {
+	ttl := cacheTTL(header, c.ttl)
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop expired entries so the cache doesn't grow without bound
+	now := time.Now()
+	for key, cached := range c.entries {
+		if now.After(cached.expires) {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[id] = cachedStory{story: story, expires: now.Add(ttl)}
+}
+
+// Works out how long a response may be cached from its Cache-Control header
+func cacheTTL(header http.Header, fallback time.Duration) time.Duration {
+	if fallback <= 0 {
+		return 0
+	}
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.TrimSpace(strings.ToLower(directive))
+		if directive == "no-store" {
+			return 0
+		}
+		if value, ok := strings.CutPrefix(directive, "max-age="); ok {
+			if seconds, err := strconv.Atoi(value); err == nil {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return fallback
+}
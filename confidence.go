@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Confidence recorded when the model doesn't give one
+const neutralConfidence = 0.5
+
+// Parses the model's confidence, given as a fraction such as "0.8" or a
+// percentage such as "80%", clamped to 0–1. Missing or unreadable values
+// yield neutralConfidence.
+func parseConfidence(text string) float64 {
+	text = strings.TrimSpace(text)
+	percent := strings.HasSuffix(text, "%")
+	if fields := strings.Fields(strings.TrimSuffix(text, "%")); len(fields) > 0 {
+		text = fields[0]
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return neutralConfidence
+	}
+	if percent || value > 1 {
+		value /= 100
+	}
+	return min(max(value, 0), 1)
+}
+
+// Draws a confidence as a ten-cell bar followed by its value
+func confidenceBar(confidence float64) string {
+	filled := int(confidence*10 + 0.5)
+	return fmt.Sprintf("%s%s %.2f", strings.Repeat("█", filled), strings.Repeat("░", 10-filled), confidence)
+}
+
+// Returns why an analyzed insight is dropped under -min-confidence, or ""
+// to show it
+func lowConfidence(cfg Config, insight HighValueInsight) string {
+	if cfg.MinConfidence > 0 && insight.Confidence < cfg.MinConfidence {
+		return fmt.Sprintf("confidence %.2f below -min-confidence", insight.Confidence)
+	}
+	return ""
+}
@@ -0,0 +1,596 @@
+package main
+
+import (
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Runtime settings parsed from the command line
+type Config struct {
+	Headless  bool
+	FadeSteps int
+	FadeStart string
+	FadeEnd   string
+	Theme     string
+	ThemeSet  bool // -theme was passed, so it wins over the -state-file theme
+	MaxAge    time.Duration
+	DumpFile  string
+
+	KeywordWeightsFile string
+	MinKeywordScore    int
+	AnalyzeOrder       string
+	SeenFile           string
+	NoPersist          bool
+	Quiet              bool
+	QuietLevel         string
+	LogOutput          string
+	KeepRaw            bool
+	TitleWidth         int
+	Jitter             float64
+	ItemCacheTTL       time.Duration
+	OllamaURL          string   // The first of OllamaURLs, for -list-models
+	OllamaURLs         []string // Servers analysis is spread across
+	ListModels         bool
+	JSON               bool
+	Since              time.Time // Zero unless -since was given
+	SinceFile          string
+	UserAgent          string
+	GroupLow           int
+	MaxConcurrent      int
+	StateFile          string
+	EngagementBoost    bool
+	BoostMinScore      int
+	BoostMinComments   int
+	Lang               string
+	Stdin              bool
+	Heartbeat          time.Duration
+	StickyTTL          time.Duration
+	Lobsters           bool
+	RSSFeeds           []string
+	FetchWorkers       int
+	AnalyzeWorkers     int
+	EscalateCount      int
+	EscalateWindow     time.Duration
+	IDsFile            string
+	CycleTimeout       time.Duration
+	CycleTimeoutPolicy string
+	ShowSkipped        bool
+	CACert             string
+	InsecureSkipVerify bool
+	FeedTitle          string
+	FeedSubtitle       string
+	Digest             bool
+	DigestSize         int
+	IncludeRegexText   string
+	ExcludeRegexText   string
+	ShowDomain         bool
+	SinkSpecs          []string
+	LogFile            string
+	ShowLatency        bool
+	Warmup             bool
+	ValidateConfig     bool
+	EntryTTL           time.Duration
+	RulesFile          string
+	HeaderSpecs        []string
+	MaxPending         int
+	TimeBasedFade      time.Duration
+	MinConfidence      float64
+	MaxBodyBytes       int64
+	AnalyzeURL         string
+	AnalyzeTitle       string
+	MinDisplay         time.Duration
+	SummaryWindow      int
+	PauseBacklog       int
+	TitleField         string
+	URLField           string
+	ShowGradient       bool
+	RepostPolicy       string
+	OnBackendDown      string
+	Focus              string
+	HideURLs           bool
+	MinOllamaVersion   string
+	StrictVersion      bool
+	Backfill           int
+	Webhook            string
+	WebhookHeaderSpecs []string
+	WebhookMinPriority string
+	WebhookTemplate    string
+	WebhookRetries     int
+	Model              string
+	OllamaTimeout      time.Duration
+	Interval           time.Duration
+	MaxEntries         int
+	FetchCount         int
+	APIBase            string
+	RepostWindow       time.Duration
+
+	// Keyword weights loaded from KeywordWeightsFile, nil when unset
+	KeywordWeights map[string]int
+
+	// Priority overrides loaded from RulesFile, nil when unset
+	Rules []rule
+
+	// Compiled -include-regex and -exclude-regex, nil when unset
+	IncludeRegex *regexp.Regexp
+	ExcludeRegex *regexp.Regexp
+
+	// Sources built from -lobsters and -rss, HN first
+	Sources []Source
+
+	// Sinks opened by openSinks, nil when there are none
+	Sinks Sink
+
+	// Headers parsed from HeaderSpecs, by host
+	Headers map[string]http.Header
+
+	// System roots plus the certificates loaded from CACert, nil when unset
+	RootCAs *x509.CertPool
+}
+
+// Reports whether to process a single batch and exit rather than poll forever
+func (cfg Config) Once() bool {
+	return !cfg.Since.IsZero() || cfg.SinceFile != "" || cfg.Stdin || cfg.IDsFile != "" || cfg.AnalyzeURL != ""
+}
+
+// Returns the Ollama server from OLLAMA_HOST, read the way Ollama itself
+// reads it: the scheme defaults to http and the port to 11434, or to 80 and
+// 443 when the scheme is given. Without OLLAMA_HOST it's the local server.
+func defaultOllamaURL() string {
+	host := strings.TrimSpace(os.Getenv("OLLAMA_HOST"))
+	if host == "" {
+		return "http://localhost:11434"
+	}
+
+	scheme, hostport, ok := strings.Cut(host, "://")
+	defaultPort := "11434"
+	switch {
+	case !ok:
+		scheme, hostport = "http", host
+	case scheme == "http":
+		defaultPort = "80"
+	case scheme == "https":
+		defaultPort = "443"
+	}
+	hostport, path, _ := strings.Cut(hostport, "/")
+
+	name, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		name, port = hostport, defaultPort
+		if i := strings.LastIndex(hostport, ":"); net.ParseIP(strings.Trim(hostport, "[]")) == nil && i > 0 {
+			// An unbracketed IPv6 literal with a port, such as ::1:11434
+			if _, perr := strconv.Atoi(hostport[i+1:]); perr == nil && net.ParseIP(hostport[:i]) != nil {
+				name, port = hostport[:i], hostport[i+1:]
+			}
+		}
+	}
+	name = strings.Trim(name, "[]")
+	if name == "" {
+		name = "127.0.0.1"
+	}
+
+	u := scheme + "://" + net.JoinHostPort(name, port)
+	if path != "" {
+		u += "/" + path
+	}
+	return u
+}
+
+// Parses command-line flags into a Config and validates the values
+func parseConfig() (Config, error) {
+	var cfg Config
+
+	flag.BoolVar(&cfg.Headless, "headless", false, "Print the feed to stdout instead of running the TUI")
+	flag.IntVar(&cfg.FadeSteps, "fade-steps", len(fadeLevels), "Number of fade steps from newest to oldest entry")
+	flag.StringVar(&cfg.FadeStart, "fade-start", "", "Color of the newest entry, as a color name or #rrggbb (default white)")
+	flag.StringVar(&cfg.FadeEnd, "fade-end", "", "Color of the oldest entry, as a color name or #rrggbb (default black)")
+	flag.StringVar(&cfg.Theme, "theme", "default", "Color theme: default, matrix, amber or mono")
+	flag.DurationVar(&cfg.MaxAge, "max-age", 0, "Skip stories submitted longer ago than this (0 disables)")
+	flag.StringVar(&cfg.DumpFile, "dump-file", "", "File to write the insight dump to on SIGUSR1, Unix only (default stderr, or intelstream-dump.json in the temp dir for the TUI)")
+	flag.StringVar(&cfg.KeywordWeightsFile, "keyword-weights", "", "File of keyword=weight pairs used to pre-filter titles before analysis")
+	flag.IntVar(&cfg.MinKeywordScore, "min-keyword-score", 1, "Minimum keyword score a title needs to be analyzed (requires -keyword-weights)")
+	flag.StringVar(&cfg.AnalyzeOrder, "analyze-order", "fifo", "Order to analyze each cycle's stories in: fifo, lifo or score-desc")
+	flag.StringVar(&cfg.SeenFile, "seen-file", "", "File to persist seen stories in across restarts (default intelstream/seen.json in the user config directory)")
+	flag.BoolVar(&cfg.NoPersist, "no-persist", false, "Don't load or save seen stories, so every run starts fresh")
+	flag.BoolVar(&cfg.Quiet, "quiet", false, "Keep error entries out of the feed; they are still logged and counted")
+	flag.StringVar(&cfg.QuietLevel, "quiet-level", "all", "Errors suppressed by -quiet: all, or transient for network and server errors only")
+	flag.StringVar(&cfg.LogOutput, "log-output", "", "File to append diagnostic logs to (default stderr when headless, discarded in the TUI)")
+	flag.BoolVar(&cfg.KeepRaw, "keep-raw", false, "Keep the raw model response for the detail view")
+	flag.IntVar(&cfg.TitleWidth, "title-width", 120, "Truncate titles longer than this many characters in the feed (0 disables)")
+	flag.Float64Var(&cfg.Jitter, "jitter", 0, "Randomize each poll interval by up to this fraction, e.g. 0.2 for ±20%")
+	flag.DurationVar(&cfg.ItemCacheTTL, "item-cache-ttl", 15*time.Minute, "How long to cache HN item lookups, unless the response says otherwise (0 disables)")
+	ollamaURLs := urlList{urls: []string{defaultOllamaURL()}}
+	flag.Var(&ollamaURLs, "ollama-url", "Base URL of the Ollama server; repeat to spread analysis across several (default $OLLAMA_HOST or http://localhost:11434)")
+	flag.BoolVar(&cfg.ListModels, "list-models", false, "List the models available on the Ollama server and exit")
+	flag.BoolVar(&cfg.JSON, "json", false, "Print machine-readable JSON output")
+	flag.Func("since", "Analyze only stories submitted after this RFC 3339 time, print them and exit", func(value string) error {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		cfg.Since = since
+		return nil
+	})
+	flag.StringVar(&cfg.SinceFile, "since-file", "", "Like -since, reading the time from this file and recording the current run's start time in it")
+	flag.StringVar(&cfg.UserAgent, "user-agent", "intelstream/"+version+" (+https://github.com/dmgedgoods/intel_streamer)", "User-Agent header sent with every outbound request")
+	flag.IntVar(&cfg.GroupLow, "group-low", 0, "Collapse runs of this many or more Low-priority items from one cycle into a summary line (0 disables)")
+	flag.IntVar(&cfg.MaxConcurrent, "max-concurrent-requests", 8, "Maximum number of outbound HTTP requests in flight at once")
+	flag.StringVar(&cfg.StateFile, "state-file", "", "File to save tags, read marks, the tag filter and the theme in on exit and restore them from on launch (disabled when empty)")
+	flag.BoolVar(&cfg.EngagementBoost, "engagement-boost", false, "Raise the model's priority one level for stories with exceptional HN engagement")
+	flag.IntVar(&cfg.BoostMinScore, "boost-min-score", 500, "Score at which -engagement-boost applies (0 ignores score)")
+	flag.IntVar(&cfg.BoostMinComments, "boost-min-comments", 250, "Comment count at which -engagement-boost applies (0 ignores comments)")
+	flag.StringVar(&cfg.Lang, "lang", "English", "Language the model writes summaries in; priority levels stay in English")
+	flag.BoolVar(&cfg.Stdin, "stdin", false, "Analyze JSON-lines items ({\"title\":...,\"url\":...}) read from stdin instead of HN, exiting at end of input")
+	flag.DurationVar(&cfg.Heartbeat, "heartbeat", 0, "Add a \"still monitoring\" entry to the feed after this long without new entries (0 disables)")
+	flag.DurationVar(&cfg.StickyTTL, "sticky-ttl", time.Hour, "Clear the sticky top-priority headline after this long without a replacement (0 keeps it)")
+	flag.BoolVar(&cfg.Lobsters, "lobsters", false, "Also analyze the newest Lobste.rs stories")
+	flag.Func("rss", "Also analyze the items of this RSS or Atom feed, e.g. a subreddit's .rss URL (repeatable)", func(value string) error {
+		cfg.RSSFeeds = append(cfg.RSSFeeds, value)
+		return nil
+	})
+	flag.IntVar(&cfg.FetchWorkers, "fetch-workers", 8, "Maximum number of HN items fetched in parallel; cheap, since fetching is I/O-bound")
+	flag.IntVar(&cfg.AnalyzeWorkers, "analyze-workers", 1, "Maximum number of stories analyzed in parallel; keep at 1-2 unless the model server has capacity to spare")
+	flag.IntVar(&cfg.EscalateCount, "escalate-count", 3, "Show a possible-incident banner when this many High-priority insights arrive within -escalate-window (0 disables)")
+	flag.DurationVar(&cfg.EscalateWindow, "escalate-window", 10*time.Minute, "Window High-priority insights are counted over for -escalate-count")
+	flag.StringVar(&cfg.IDsFile, "ids-file", "", "Analyze the HN items listed in this file (IDs separated by whitespace or commas) once instead of the live top list")
+	flag.DurationVar(&cfg.CycleTimeout, "cycle-timeout", 0, "Cut a fetch-and-analyze cycle short after this long (0 disables)")
+	flag.StringVar(&cfg.CycleTimeoutPolicy, "cycle-timeout-policy", "carry", "What happens to stories a timed-out cycle didn't analyze: carry (retry next cycle) or drop")
+	flag.BoolVar(&cfg.ShowSkipped, "show-skipped", false, "Show stories dropped by the pre-filters, with the reason, to help tune them")
+	flag.StringVar(&cfg.CACert, "ca-cert", "", "PEM file of extra CA certificates to trust for HTTPS, e.g. a TLS-intercepting proxy's")
+	flag.BoolVar(&cfg.InsecureSkipVerify, "insecure-skip-verify", false, "DANGEROUS: don't verify HTTPS certificates, for testing against self-signed servers only")
+	flag.StringVar(&cfg.FeedTitle, "feed-title", "High-Value Intelligence Feed", "Title of the feed pane; {model}, {sources} and {version} are replaced with their current values")
+	flag.StringVar(&cfg.FeedSubtitle, "feed-subtitle", "", "Optional subtitle shown after the feed title, with the same placeholders")
+	flag.BoolVar(&cfg.Digest, "digest", false, "Summarize each cycle's stories together in one executive digest entry instead of triaging them one by one")
+	flag.IntVar(&cfg.DigestSize, "digest-size", 10, "Maximum number of stories fetched for and included in each digest")
+	flag.StringVar(&cfg.IncludeRegexText, "include-regex", "", "Only analyze stories whose title matches this regular expression; checked after -exclude-regex and before -keyword-weights")
+	flag.StringVar(&cfg.ExcludeRegexText, "exclude-regex", "", "Skip stories whose title matches this regular expression; takes precedence over every other filter")
+	flag.BoolVar(&cfg.ShowDomain, "show-domain", false, "Prefix each title with its source domain, colored consistently per domain")
+	flag.StringVar(&cfg.LogFile, "log-file", "", "Append each insight to this JSONL file with a timestamp, for reviewing past stories (analysis failures are left out)")
+	flag.Func("sink", "Also send each insight to this destination: stdout or jsonl:PATH (repeatable; stdout garbles the TUI)", func(value string) error {
+		cfg.SinkSpecs = append(cfg.SinkSpecs, value)
+		return nil
+	})
+	flag.Func("header", "Send this header with one source's requests, as source:Name: value with source hn or lobsters (repeatable)", func(value string) error {
+		cfg.HeaderSpecs = append(cfg.HeaderSpecs, value)
+		return nil
+	})
+	flag.BoolVar(&cfg.ShowLatency, "show-latency", false, "Show how long each analysis took next to its priority in the feed")
+	flag.BoolVar(&cfg.Warmup, "warmup", false, "Load the model with a trivial prompt before the first cycle so the first analysis isn't slow")
+	flag.BoolVar(&cfg.ValidateConfig, "validate-config", false, "Validate the flags and the files they name, print the effective configuration and exit")
+	flag.DurationVar(&cfg.EntryTTL, "entry-ttl", 0, "Remove feed entries after they've been shown this long, whatever the entry count (0 keeps them until evicted)")
+	flag.StringVar(&cfg.RulesFile, "rules", "", "File of priority override rules, such as \"High: keyword=zero-day domain=example.com\", applied after analysis")
+	flag.IntVar(&cfg.MaxPending, "max-pending", 0, "Show headlines unanalyzed, with priority N/A, for cycles bringing more than this many stories to analyze (0 disables)")
+	flag.DurationVar(&cfg.TimeBasedFade, "time-based-fade", 0, "Fade entries by time in the feed instead of position, reaching the last fade step after this long (0 fades by position)")
+	flag.Float64Var(&cfg.MinConfidence, "min-confidence", 0, "Drop analyzed stories whose model confidence is below this, from 0 to 1 (0 keeps all)")
+	flag.Int64Var(&cfg.MaxBodyBytes, "max-body-bytes", 4<<20, "Largest response body accepted from any source, in bytes")
+	flag.StringVar(&cfg.AnalyzeURL, "analyze-url", "", "Analyze this one URL, print the insight and exit")
+	flag.StringVar(&cfg.AnalyzeTitle, "analyze-title", "", "Title to analyze -analyze-url with (default the page's <title>)")
+	flag.DurationVar(&cfg.MinDisplay, "min-display", 0, "Keep each entry at full brightness for at least this long after it arrives, however many newer entries follow")
+	flag.IntVar(&cfg.SummaryWindow, "summary-window", 0, "Collapse summaries identical to one of the last this many, to spot repeated model boilerplate (0 disables)")
+	flag.IntVar(&cfg.PauseBacklog, "pause-backlog", 100, "Most stories queued for analysis while it's paused with P or waiting on -on-backend-down=retry; later ones stay as headlines")
+	flag.StringVar(&cfg.TitleField, "title-field", "title", "JSON key item titles are read from, for HN-compatible APIs")
+	flag.StringVar(&cfg.URLField, "url-field", "url", "JSON key item URLs are read from, for HN-compatible APIs")
+	flag.BoolVar(&cfg.ShowGradient, "show-gradient", false, "Print the fade colors the theme and fade flags produce as sample lines and exit")
+	flag.StringVar(&cfg.RepostPolicy, "repost-policy", "show", "What to do with a story whose title matches one fetched within -repost-window: show, skip, or merge into the earlier entry")
+	flag.DurationVar(&cfg.RepostWindow, "repost-window", 24*time.Hour, "How long titles are remembered for -repost-policy")
+	flag.StringVar(&cfg.OnBackendDown, "on-backend-down", "", "Check Ollama and the model at startup, and if unavailable: exit, headline-only to show unanalyzed headlines, or retry to show headlines until it comes up (unset skips the check)")
+	flag.StringVar(&cfg.Focus, "focus", "cybersecurity", "Domain stories are analyzed for: "+strings.Join(focusNames, ", "))
+	flag.BoolVar(&cfg.HideURLs, "hide-urls", false, "Leave the URL line out of feed entries, showing the domain instead (toggle with u)")
+	flag.StringVar(&cfg.MinOllamaVersion, "min-ollama-version", "0.1.33", "Warn at startup if an Ollama server reports an older version than this (empty skips the check, e.g. for other backends)")
+	flag.BoolVar(&cfg.StrictVersion, "require-ollama-version", false, "Exit instead of warning when an Ollama server is older than -min-ollama-version")
+	flag.IntVar(&cfg.Backfill, "backfill", 0, "Fetch and analyze up to this many top stories in the first cycle, within -analyze-workers, to fill the feed on launch (0 disables)")
+	flag.StringVar(&cfg.Webhook, "webhook", "", "POST each insight as JSON to this URL, in the background, e.g. for a SOAR or SIEM pipeline")
+	flag.Func("webhook-header", "Send this header, as Name: value, with each -webhook request (repeatable)", func(value string) error {
+		cfg.WebhookHeaderSpecs = append(cfg.WebhookHeaderSpecs, value)
+		return nil
+	})
+	flag.StringVar(&cfg.WebhookMinPriority, "webhook-min-priority", "", "Only send insights of at least this priority to -webhook: High, Medium or Low (default all)")
+	flag.StringVar(&cfg.WebhookTemplate, "webhook-template", "", "Go template file to render each -webhook body from instead of the JSON record, e.g. {\"text\": {{json .Title}}}")
+	flag.IntVar(&cfg.WebhookRetries, "webhook-retries", 3, "Times a -webhook delivery is retried after a network error, 429 or 5xx response")
+	flag.StringVar(&cfg.Model, "model", "llama3.2", "Ollama model to analyze stories with")
+	flag.DurationVar(&cfg.OllamaTimeout, "ollama-timeout", 5*time.Minute, "Give up on a model request after this long, so a hung model doesn't stall the feed (0 disables)")
+	flag.DurationVar(&cfg.Interval, "interval", 5*time.Second, "Time to wait between fetches")
+	flag.IntVar(&cfg.MaxEntries, "max-entries", 20, "Maximum number of entries kept in the feed")
+	flag.IntVar(&cfg.FetchCount, "fetch-count", 1, fmt.Sprintf("Number of new stories fetched each cycle, at most %d", maxFetchCount))
+	flag.StringVar(&cfg.APIBase, "api-base", "https://hacker-news.firebaseio.com/v0", "Base URL of the Hacker News API, e.g. a local mock server for testing")
+	flag.Parse()
+	flag.Visit(func(f *flag.Flag) { cfg.ThemeSet = cfg.ThemeSet || f.Name == "theme" })
+	if cfg.NoPersist {
+		cfg.SeenFile = ""
+	} else if cfg.SeenFile == "" {
+		cfg.SeenFile = defaultSeenFile()
+	}
+	cfg.OllamaURLs = ollamaURLs.urls
+	cfg.OllamaURL = cfg.OllamaURLs[0]
+
+	if cfg.FadeSteps < 1 {
+		return cfg, fmt.Errorf("-fade-steps must be at least 1, got %d", cfg.FadeSteps)
+	}
+
+	if cfg.MaxAge < 0 {
+		return cfg, fmt.Errorf("-max-age must not be negative, got %v", cfg.MaxAge)
+	}
+
+	if cfg.Jitter < 0 || cfg.Jitter >= 1 {
+		return cfg, fmt.Errorf("-jitter must be at least 0 and less than 1, got %v", cfg.Jitter)
+	}
+
+	switch cfg.AnalyzeOrder {
+	case "fifo", "lifo", "score-desc":
+	default:
+		return cfg, fmt.Errorf("-analyze-order must be fifo, lifo or score-desc, got %q", cfg.AnalyzeOrder)
+	}
+
+	if cfg.FetchWorkers < 1 || cfg.AnalyzeWorkers < 1 {
+		return cfg, fmt.Errorf("-fetch-workers and -analyze-workers must be at least 1")
+	}
+
+	if cfg.MaxPending < 0 {
+		return cfg, fmt.Errorf("-max-pending must not be negative, got %d", cfg.MaxPending)
+	}
+
+	if cfg.DigestSize < 1 {
+		return cfg, fmt.Errorf("-digest-size must be at least 1, got %d", cfg.DigestSize)
+	}
+
+	if cfg.MaxBodyBytes < 1 {
+		return cfg, fmt.Errorf("-max-body-bytes must be at least 1, got %d", cfg.MaxBodyBytes)
+	}
+
+	if cfg.MaxConcurrent < 1 {
+		return cfg, fmt.Errorf("-max-concurrent-requests must be at least 1, got %d", cfg.MaxConcurrent)
+	}
+
+	if cfg.BoostMinScore < 0 || cfg.BoostMinComments < 0 {
+		return cfg, fmt.Errorf("-boost-min-score and -boost-min-comments must not be negative")
+	}
+
+	if cfg.Heartbeat < 0 {
+		return cfg, fmt.Errorf("-heartbeat must not be negative, got %v", cfg.Heartbeat)
+	}
+
+	if cfg.MinConfidence < 0 || cfg.MinConfidence > 1 {
+		return cfg, fmt.Errorf("-min-confidence must be between 0 and 1, got %v", cfg.MinConfidence)
+	}
+
+	for _, key := range []string{cfg.TitleField, cfg.URLField} {
+		if strings.TrimSpace(key) != key || key == "" {
+			return cfg, fmt.Errorf("-title-field and -url-field must be non-empty JSON keys without surrounding spaces, got %q", key)
+		}
+	}
+	if cfg.TitleField == cfg.URLField {
+		return cfg, fmt.Errorf("-title-field and -url-field must differ, both are %q", cfg.TitleField)
+	}
+
+	switch cfg.OnBackendDown {
+	case "", "exit", "headline-only", "retry":
+	default:
+		return cfg, fmt.Errorf("-on-backend-down must be exit, headline-only or retry, got %q", cfg.OnBackendDown)
+	}
+
+	switch cfg.RepostPolicy {
+	case "show", "skip", "merge":
+	default:
+		return cfg, fmt.Errorf("-repost-policy must be show, skip or merge, got %q", cfg.RepostPolicy)
+	}
+	if cfg.RepostWindow <= 0 {
+		return cfg, fmt.Errorf("-repost-window must be positive, got %v", cfg.RepostWindow)
+	}
+
+	if cfg.Interval <= 0 {
+		return cfg, fmt.Errorf("-interval must be positive, got %v", cfg.Interval)
+	}
+	if cfg.MaxEntries < 1 {
+		return cfg, fmt.Errorf("-max-entries must be at least 1, got %d", cfg.MaxEntries)
+	}
+	if cfg.FetchCount < 1 || cfg.FetchCount > maxFetchCount {
+		return cfg, fmt.Errorf("-fetch-count must be between 1 and %d, got %d", maxFetchCount, cfg.FetchCount)
+	}
+	apiBase, err := url.Parse(cfg.APIBase)
+	if err != nil || apiBase.Host == "" || (apiBase.Scheme != "http" && apiBase.Scheme != "https") {
+		return cfg, fmt.Errorf("-api-base must be an http or https URL, got %q", cfg.APIBase)
+	}
+	// -header hn:... follows the API to wherever it's served from
+	sourceHosts["hn"] = apiBase.Hostname()
+
+	for _, feedURL := range cfg.RSSFeeds {
+		if u, err := url.Parse(feedURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return cfg, fmt.Errorf("-rss must be an http or https URL, got %q", feedURL)
+		}
+	}
+	cfg.Sources = buildSources(cfg)
+
+	if strings.TrimSpace(cfg.Model) == "" {
+		return cfg, fmt.Errorf("-model must not be empty")
+	}
+	if cfg.OllamaTimeout < 0 {
+		return cfg, fmt.Errorf("-ollama-timeout must not be negative, got %v", cfg.OllamaTimeout)
+	}
+
+	if cfg.Backfill < 0 {
+		return cfg, fmt.Errorf("-backfill must not be negative, got %d", cfg.Backfill)
+	}
+
+	if cfg.PauseBacklog < 0 {
+		return cfg, fmt.Errorf("-pause-backlog must not be negative, got %d", cfg.PauseBacklog)
+	}
+
+	if cfg.SummaryWindow < 0 {
+		return cfg, fmt.Errorf("-summary-window must not be negative, got %d", cfg.SummaryWindow)
+	}
+
+	if cfg.MinDisplay < 0 {
+		return cfg, fmt.Errorf("-min-display must not be negative, got %v", cfg.MinDisplay)
+	}
+
+	if cfg.TimeBasedFade < 0 {
+		return cfg, fmt.Errorf("-time-based-fade must not be negative, got %v", cfg.TimeBasedFade)
+	}
+
+	if cfg.EntryTTL < 0 {
+		return cfg, fmt.Errorf("-entry-ttl must not be negative, got %v", cfg.EntryTTL)
+	}
+
+	if cfg.StickyTTL < 0 {
+		return cfg, fmt.Errorf("-sticky-ttl must not be negative, got %v", cfg.StickyTTL)
+	}
+
+	if cfg.EscalateCount < 0 || cfg.EscalateWindow <= 0 {
+		return cfg, fmt.Errorf("-escalate-count must not be negative and -escalate-window must be positive")
+	}
+
+	if cfg.CycleTimeout < 0 {
+		return cfg, fmt.Errorf("-cycle-timeout must not be negative, got %v", cfg.CycleTimeout)
+	}
+	if cfg.CycleTimeoutPolicy != "carry" && cfg.CycleTimeoutPolicy != "drop" {
+		return cfg, fmt.Errorf("-cycle-timeout-policy must be carry or drop, got %q", cfg.CycleTimeoutPolicy)
+	}
+
+	if cfg.ItemCacheTTL < 0 {
+		return cfg, fmt.Errorf("-item-cache-ttl must not be negative, got %v", cfg.ItemCacheTTL)
+	}
+
+	if cfg.QuietLevel != "all" && cfg.QuietLevel != "transient" {
+		return cfg, fmt.Errorf("-quiet-level must be all or transient, got %q", cfg.QuietLevel)
+	}
+
+	if cfg.IncludeRegexText != "" {
+		re, err := regexp.Compile(cfg.IncludeRegexText)
+		if err != nil {
+			return cfg, fmt.Errorf("-include-regex: %v", err)
+		}
+		cfg.IncludeRegex = re
+	}
+	if cfg.ExcludeRegexText != "" {
+		re, err := regexp.Compile(cfg.ExcludeRegexText)
+		if err != nil {
+			return cfg, fmt.Errorf("-exclude-regex: %v", err)
+		}
+		cfg.ExcludeRegex = re
+	}
+
+	if cfg.KeywordWeightsFile != "" {
+		weights, err := loadKeywordWeights(cfg.KeywordWeightsFile)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.KeywordWeights = weights
+	}
+
+	// Checked here rather than in the flag callback, whose errors would echo
+	// the header's value
+	cfg.Headers = map[string]http.Header{}
+	for i, spec := range cfg.HeaderSpecs {
+		if err := addSourceHeader(cfg.Headers, spec); err != nil {
+			return cfg, fmt.Errorf("-header #%d: %v", i+1, err)
+		}
+	}
+
+	if cfg.RulesFile != "" {
+		rules, err := loadRules(cfg.RulesFile)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Rules = rules
+	}
+
+	if cfg.InsecureSkipVerify && cfg.CACert != "" {
+		return cfg, fmt.Errorf("-insecure-skip-verify and -ca-cert can't be combined; -ca-cert keeps verification on")
+	}
+
+	// Sinks are only checked here; main opens them once it knows it's
+	// running, so -validate-config and the like create no files
+	for _, spec := range cfg.SinkSpecs {
+		if _, _, err := parseSinkSpec(spec); err != nil {
+			return cfg, err
+		}
+	}
+	if cfg.Webhook != "" {
+		if _, err := parseWebhookFlags(cfg); err != nil {
+			return cfg, err
+		}
+	} else if len(cfg.WebhookHeaderSpecs) > 0 || cfg.WebhookMinPriority != "" || cfg.WebhookTemplate != "" {
+		return cfg, fmt.Errorf("-webhook-header, -webhook-min-priority and -webhook-template require -webhook")
+	}
+	if cfg.CACert != "" {
+		pool, err := loadCACert(cfg.CACert)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.RootCAs = pool
+	}
+
+	if cfg.AnalyzeURL != "" {
+		if u, err := url.Parse(cfg.AnalyzeURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return cfg, fmt.Errorf("-analyze-url must be an http or https URL, got %q", cfg.AnalyzeURL)
+		}
+	} else if cfg.AnalyzeTitle != "" {
+		return cfg, fmt.Errorf("-analyze-title requires -analyze-url")
+	}
+
+	for _, ollamaURL := range cfg.OllamaURLs {
+		if u, err := url.Parse(ollamaURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return cfg, fmt.Errorf("-ollama-url must be an http or https URL, got %q", ollamaURL)
+		}
+	}
+
+	if cfg.MinOllamaVersion != "" && !validVersion(cfg.MinOllamaVersion) {
+		return cfg, fmt.Errorf("-min-ollama-version must be a version like 0.5.7, got %q", cfg.MinOllamaVersion)
+	} else if cfg.StrictVersion && cfg.MinOllamaVersion == "" {
+		return cfg, fmt.Errorf("-require-ollama-version needs -min-ollama-version")
+	}
+
+	if _, ok := focuses[cfg.Focus]; !ok {
+		return cfg, fmt.Errorf("-focus must be one of %s, got %q", strings.Join(focusNames, ", "), cfg.Focus)
+	}
+
+	if _, ok := themes[cfg.Theme]; !ok {
+		return cfg, fmt.Errorf("-theme must be one of %s, got %q", strings.Join(themeNames, ", "), cfg.Theme)
+	}
+
+	// Catch bad fade colors now rather than on the first redraw
+	if _, err := fadeGradient(cfg.FadeStart, cfg.FadeEnd, cfg.FadeSteps); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// Opens the -sink, -log-file and -webhook outputs, returning nil when none
+// are set
+func openSinks(cfg Config) (Sink, error) {
+	var sinks multiSink
+	for _, spec := range cfg.SinkSpecs {
+		sink, err := openSink(spec)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, namedSink{name: spec, Sink: sink})
+	}
+	if cfg.LogFile != "" {
+		sink, err := openLogFile(cfg.LogFile)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, namedSink{name: "log-file", Sink: sink})
+	}
+	if cfg.Webhook != "" {
+		sink, err := openWebhook(cfg)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, namedSink{name: "webhook", Sink: sink})
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return sinks, nil
+}
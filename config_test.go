@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestDefaultOllamaURL(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"", "http://localhost:11434"},
+		{"::1", "http://[::1]:11434"},
+		{"[::1]:11434", "http://[::1]:11434"},
+		{"[::1]", "http://[::1]:11434"},
+		{"::1:11434", "http://[::1]:11434"},
+		{"0.0.0.0", "http://0.0.0.0:11434"},
+		{"localhost", "http://localhost:11434"},
+		{"localhost:1234", "http://localhost:1234"},
+		{"https://host", "https://host:443"},
+		{"http://host", "http://host:80"},
+		{"http://host:8080/ollama", "http://host:8080/ollama"},
+		{":11434", "http://127.0.0.1:11434"},
+	}
+	for _, tt := range tests {
+		t.Setenv("OLLAMA_HOST", tt.host)
+		if got := defaultOllamaURL(); got != tt.want {
+			t.Errorf("OLLAMA_HOST=%q: defaultOllamaURL() = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
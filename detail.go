@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Opens the expanded view of the selected entry
+func (t *tui) showDetail() {
+	entry, ok := t.selectedEntry()
+	if !ok {
+		t.flash("[yellow]No entry selected[-]")
+		return
+	}
+
+	showRaw := false
+	detailView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true).
+		SetText(formatDetail(entry, showRaw))
+	detailView.SetBorder(true).SetTitle("Details")
+	detailView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyEnter:
+			t.pages.RemovePage("detail")
+		case event.Rune() == 'r':
+			showRaw = !showRaw
+			detailView.SetText(formatDetail(entry, showRaw)).ScrollToBeginning()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	t.pages.AddPage("detail", detailView, true, true)
+}
+
+// Formats the full contents of an entry for the detail view, optionally
+// including the raw model response
+func formatDetail(entry feedEntry, showRaw bool) string {
+	if entry.Err != nil {
+		var outage *allSourcesError
+		if errors.As(entry.Err, &outage) {
+			return fmt.Sprintf("[red]Error: %v[-]\n\n%s\n[gray]Esc to close[-]", entry.Err, tview.Escape(outage.Details()))
+		}
+		return fmt.Sprintf("[red]Error: %v[-]\n\n[gray]Esc to close[-]", entry.Err)
+	}
+	if entry.Notice != "" {
+		return entry.Notice + "\n\n[gray]Esc to close[-]"
+	}
+	if entry.SkipReason != "" {
+		return fmt.Sprintf("[gray]Skipped: %s[-]\n\n[green]%s[-]\n%s\n\n[gray]Esc to close[-]",
+			entry.SkipReason, entry.Insight.Title, entry.Insight.URL)
+	}
+
+	if entry.Group != nil {
+		var b strings.Builder
+		fmt.Fprintf(&b, "[yellow]%d collapsed Low-priority items[-]\n\n", len(entry.Group))
+		for _, member := range entry.Group {
+			fmt.Fprintf(&b, "[green]%s[-]\n%s\n\n", member.Insight.Title, member.Insight.URL)
+		}
+		b.WriteString("[gray]x in the feed to expand, Esc to close[-]")
+		return b.String()
+	}
+
+	insight := entry.Insight
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow]Priority: %s[-]\n\n", insight.Priority)
+	fmt.Fprintf(&b, "[green]%s[-]\n%s\n", insight.Title, insight.URL)
+	if insight.DiscussionURL != "" && insight.DiscussionURL != insight.URL {
+		fmt.Fprintf(&b, "[gray]Discussion:[-] %s\n", insight.DiscussionURL)
+	}
+	fmt.Fprintf(&b, "\n%s\n", insight.Summary)
+	if insight.AnalysisDuration > 0 {
+		fmt.Fprintf(&b, "\n[gray]Analyzed in %v[-]\n", insight.AnalysisDuration.Round(time.Millisecond))
+	}
+	if insight.Confidence > 0 {
+		fmt.Fprintf(&b, "\n[gray]Confidence:[-] %s\n", confidenceBar(insight.Confidence))
+	}
+	if insight.OverriddenFrom != "" {
+		fmt.Fprintf(&b, "\n[gray]Priority set by analyst; the model said:[-] %s\n", tview.Escape(insight.OverriddenFrom))
+	}
+	if insight.Rule != "" {
+		fmt.Fprintf(&b, "\n[gray]Priority set by rule:[-] %s\n", tview.Escape(insight.Rule))
+	}
+	if insight.Rationale != "" {
+		fmt.Fprintf(&b, "\n[aqua]Why this priority:[-] %s\n", insight.Rationale)
+	}
+
+	if showRaw {
+		b.WriteString("\n[aqua]Raw model response:[-]\n")
+		if insight.Raw != "" {
+			b.WriteString(tview.Escape(insight.Raw))
+		} else {
+			b.WriteString("[gray]Not kept; run with -keep-raw to keep raw responses[-]\n")
+		}
+	}
+
+	b.WriteString("\n[gray]r to toggle raw response, Esc to close[-]")
+	return b.String()
+}
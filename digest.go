@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Longest story list sent in one digest prompt, to stay well inside small
+// models' context windows; stories past it are left out of the digest
+const maxDigestPromptBytes = 6000
+
+// Asks the model for a single executive summary of a cycle's stories. The
+// result is a digest insight with no ID or URL whose title says how many
+// stories it covers.
+func digestWithOllama(ctx context.Context, cfg Config, stories []Story) (HighValueInsight, error) {
+	var list strings.Builder
+	included := 0
+	for _, story := range stories {
+		line := fmt.Sprintf("%d. %s (%s)\n", included+1, truncateText(story.Title, 200), story.URL)
+		if included > 0 && list.Len()+len(line) > maxDigestPromptBytes {
+			break
+		}
+		list.WriteString(line)
+		included++
+	}
+
+	insight := HighValueInsight{Title: fmt.Sprintf("Digest of %d stories", included), Digest: true}
+	f := focuses[cfg.Focus]
+	prompt := fmt.Sprintf("You are an expert %s briefing management. Write a short executive summary of the most important developments in %s in the following headlines, skipping irrelevant ones. Start with a single line giving the overall priority (High, Medium or Low), then the summary.%s\n\n%s", f.Analyst, f.Field, languageInstruction(cfg.Lang), list.String())
+
+	output, err := runOllama(ctx, prompt)
+	if err != nil {
+		return insight, err
+	}
+
+	output = strings.TrimSpace(output)
+	first, rest, _ := strings.Cut(output, "\n")
+	insight.Priority = priorityLevel(first)
+	insight.Summary = strings.TrimSpace(rest)
+	if insight.Priority == "" || insight.Summary == "" {
+		// No usable priority line; keep the whole reply as the summary
+		insight.Summary = output
+	}
+	if insight.Priority == "" {
+		// Ranked lowest, like a story reply without a level
+		insight.Priority = priorityLow
+	}
+	if cfg.KeepRaw {
+		insight.Raw = output
+	}
+	return insight, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestDigestWithOllamaPriority(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		wantPriority string
+		wantSummary  string
+	}{
+		{"plain level line", "High\nTwo zero-days in VPN appliances.", priorityHigh, "Two zero-days in VPN appliances."},
+		{"decorated level line", "**Overall priority: Medium**\nA quiet day.", priorityMedium, "A quiet day."},
+		{"single line", "Priority: Low, nothing notable.", priorityLow, "Priority: Low, nothing notable."},
+		{"no level", "Nothing notable today.\nJust routine releases.", priorityLow, "Nothing notable today.\nJust routine releases."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeOllama(t, func(string) (int, string) { return http.StatusOK, tt.output })
+
+			insight, err := digestWithOllama(context.Background(), Config{Focus: "cybersecurity"}, testStories(3))
+			if err != nil {
+				t.Fatalf("digestWithOllama() error = %v", err)
+			}
+			if insight.Priority != tt.wantPriority || insight.Summary != tt.wantSummary {
+				t.Errorf("digestWithOllama() = %q, %q; want %q, %q", insight.Priority, insight.Summary, tt.wantPriority, tt.wantSummary)
+			}
+			if !insight.Digest || insight.Title != "Digest of 3 stories" {
+				t.Errorf("digestWithOllama() = %+v, want a digest of 3 stories", insight)
+			}
+		})
+	}
+}
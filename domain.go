@@ -0,0 +1,32 @@
+package main
+
+import (
+	"hash/fnv"
+	"net/url"
+	"strings"
+)
+
+// Colors domain tags are drawn from; chosen to stay readable on a dark
+// background and distinct from the priority colors
+var domainPalette = []string{
+	"aqua", "fuchsia", "orange", "lightskyblue", "violet",
+	"khaki", "palegreen", "salmon", "plum", "turquoise",
+}
+
+// Returns the lowercased host of an absolute URL without any "www." prefix,
+// or "" for missing, relative or unparseable URLs
+func domainOf(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// Returns the color for a domain's tag. Colors come from a hash of the
+// domain, so a source keeps its color across entries and restarts.
+func domainColor(domain string) string {
+	h := fnv.New32a()
+	h.Write([]byte(domain))
+	return domainPalette[h.Sum32()%uint32(len(domainPalette))]
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Version of the insight record format written to JSON outputs. Bump it
+// whenever a field is added, removed or changes meaning.
+const insightSchemaVersion = 8
+
+// An insight as written to JSON outputs, tagged with the schema version so
+// downstream consumers can handle format changes
+type insightRecord struct {
+	SchemaVersion int `json:"schema_version"`
+	HighValueInsight
+}
+
+// Wraps an insight in a versioned record for output
+func newInsightRecord(insight HighValueInsight) insightRecord {
+	return insightRecord{SchemaVersion: insightSchemaVersion, HighValueInsight: insight}
+}
+
+// Writes insights as indented JSON records to the file at path, or to stderr
+// when path is empty. An existing file is overwritten with the latest snapshot.
+func dumpInsights(insights []HighValueInsight, path string) error {
+	out := os.Stderr
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	records := make([]insightRecord, len(insights))
+	for i, insight := range insights {
+		records[i] = newInsightRecord(insight)
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
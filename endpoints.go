@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// How long an endpoint whose request failed is passed over, unless every
+// endpoint is failing
+const endpointCooldown = 30 * time.Second
+
+// One of the -ollama-url servers analysis is spread across
+type ollamaEndpoint struct {
+	url       string
+	inFlight  atomic.Int64
+	requests  atomic.Int64 // Analysis requests sent, successful or not
+	failures  atomic.Int64
+	downUntil atomic.Int64 // Unix nanoseconds until which it's skipped, 0 when healthy
+}
+
+// The configured Ollama servers, set from -ollama-url at startup
+var ollamaPool struct {
+	endpoints []*ollamaEndpoint
+	next      atomic.Uint64 // Breaks ties between equally loaded endpoints
+}
+
+func setOllamaEndpoints(urls []string) {
+	ollamaPool.endpoints = nil
+	for _, u := range urls {
+		ollamaPool.endpoints = append(ollamaPool.endpoints, &ollamaEndpoint{url: u})
+	}
+}
+
+// Returns the healthy endpoint with the fewest requests in flight, taking
+// turns between equally loaded ones. If all are failing, the one that's
+// been cooling down longest is tried. The caller must call release.
+func acquireEndpoint() *ollamaEndpoint {
+	eps := ollamaPool.endpoints
+	start := int(ollamaPool.next.Add(1) % uint64(len(eps)))
+	now := time.Now().UnixNano()
+
+	var best, fallback *ollamaEndpoint
+	for i := range eps {
+		ep := eps[(start+i)%len(eps)]
+		if fallback == nil || ep.downUntil.Load() < fallback.downUntil.Load() {
+			fallback = ep
+		}
+		if ep.downUntil.Load() > now {
+			continue
+		}
+		if best == nil || ep.inFlight.Load() < best.inFlight.Load() {
+			best = ep
+		}
+	}
+	if best == nil {
+		best = fallback
+	}
+	best.inFlight.Add(1)
+	best.requests.Add(1)
+	return best
+}
+
+// Finishes a request from acquireEndpoint, recording whether the endpoint
+// served it
+func (ep *ollamaEndpoint) release(ok bool) {
+	ep.inFlight.Add(-1)
+	if ok {
+		ep.downUntil.Store(0)
+		return
+	}
+	ep.failures.Add(1)
+	ep.downUntil.Store(time.Now().Add(endpointCooldown).UnixNano())
+}
+
+// Lists each endpoint's request count for the status bar, or "" with a
+// single endpoint
+func endpointSummary() string {
+	if len(ollamaPool.endpoints) < 2 {
+		return ""
+	}
+	now := time.Now().UnixNano()
+	var parts []string
+	for _, ep := range ollamaPool.endpoints {
+		host := ep.url
+		if u, err := url.Parse(ep.url); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		part := fmt.Sprintf("%s %d", host, ep.requests.Load())
+		if ep.downUntil.Load() > now {
+			part = "[red]" + part + " down[-]"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Values of a repeatable URL flag. The first one given replaces the default.
+type urlList struct {
+	urls []string
+	set  bool
+}
+
+func (l *urlList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(l.urls, " ")
+}
+
+func (l *urlList) Set(value string) error {
+	if !l.set {
+		l.urls, l.set = nil, true
+	}
+	l.urls = append(l.urls, value)
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Returns how engaged HN readers are with a story relative to the
+// -engagement-boost thresholds; 1 or more means at least one was reached
+func engagementFactor(story Story, cfg Config) float64 {
+	factor := 0.0
+	if cfg.BoostMinScore > 0 {
+		factor = max(factor, float64(story.Score)/float64(cfg.BoostMinScore))
+	}
+	if cfg.BoostMinComments > 0 {
+		factor = max(factor, float64(story.Descendants)/float64(cfg.BoostMinComments))
+	}
+	return factor
+}
+
+// Raises the model's priority by one level for stories with exceptional
+// engagement, which tend to be breaking news the model underrates. Errors
+// from the model and unrecognized priorities are left alone.
+func boostPriority(insight HighValueInsight, story Story, cfg Config) HighValueInsight {
+	if !cfg.EngagementBoost || engagementFactor(story, cfg) < 1 {
+		return insight
+	}
+
+	var boosted string
+	switch level := priorityLevel(insight.Priority); level {
+	case priorityLow:
+		boosted = priorityMedium
+	case priorityMedium:
+		boosted = priorityHigh
+	default:
+		return insight
+	}
+	insight.Priority = fmt.Sprintf("%s (boosted from %s: %d points, %d comments)",
+		boosted, priorityLevel(insight.Priority), story.Score, story.Descendants)
+	return insight
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// An HTTP response that wasn't the JSON document we asked for
+type responseError struct {
+	StatusCode int
+	msg        string
+}
+
+func (e *responseError) Error() string {
+	return e.msg
+}
+
+// A fetch failure from one named source. The message is the underlying
+// error's; callers that need the source in it add it themselves.
+type fetchError struct {
+	Source string
+	Err    error
+}
+
+func (e fetchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e fetchError) Unwrap() error {
+	return e.Err
+}
+
+// A failed model run for one story
+type analysisError struct {
+	ID  storyID // Story being analyzed
+	Err error
+}
+
+func (e *analysisError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *analysisError) Unwrap() error {
+	return e.Err
+}
+
+// A source item that couldn't be decoded into a displayable story. It
+// matches errMalformedStory with errors.Is.
+type parseError struct {
+	ID  storyID // Item ID, "" when the item couldn't be decoded far enough to tell
+	Err error
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("%v: %v", errMalformedStory, e.Err)
+}
+
+func (e *parseError) Unwrap() error {
+	return e.Err
+}
+
+func (e *parseError) Is(target error) bool {
+	return target == errMalformedStory
+}
+
+// Reported in place of the individual errors when every source failed in
+// the same cycle. The message stays the same across cycles so repeats
+// collapse in the feed; the per-source errors are in Details.
+type allSourcesError struct {
+	Failures []fetchError
+}
+
+func (e *allSourcesError) Error() string {
+	return "all sources unavailable"
+}
+
+// Lists each source's error, one per line
+func (e *allSourcesError) Details() string {
+	var b strings.Builder
+	for _, f := range e.Failures {
+		fmt.Fprintf(&b, "%s: %v\n", f.Source, f.Err)
+	}
+	return b.String()
+}
+
+// Lets errors.As and isTransient see the underlying errors
+func (e *allSourcesError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f
+	}
+	return errs
+}
+
+// Reports whether an error is likely to clear up on its own, such as a
+// network failure or an overloaded server
+func isTransient(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var respErr *responseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode >= 500 || respErr.StatusCode == 429
+	}
+
+	return false
+}
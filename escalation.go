@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Sliding window of High-priority arrival times, used to spot a cluster of
+// high-severity stories that may mean an incident is under way
+type escalation struct {
+	mu        sync.Mutex
+	threshold int // Arrivals within window that count as a cluster; 0 disables
+	window    time.Duration
+	arrivals  []time.Time // Oldest first
+}
+
+func newEscalation(threshold int, window time.Duration) *escalation {
+	return &escalation{threshold: threshold, window: window}
+}
+
+// Records a High-priority arrival and reports whether the window now holds
+// enough of them to escalate
+func (e *escalation) record(now time.Time) bool {
+	if e.threshold == 0 {
+		return false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.arrivals = append(e.arrivals, now)
+	e.expire(now)
+	return len(e.arrivals) >= e.threshold
+}
+
+// Returns how many High-priority insights arrived within the window, and
+// whether that's enough to escalate
+func (e *escalation) active(now time.Time) (int, bool) {
+	if e.threshold == 0 {
+		return 0, false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.expire(now)
+	return len(e.arrivals), len(e.arrivals) >= e.threshold
+}
+
+// Drops arrivals older than the window. Called with e.mu held.
+func (e *escalation) expire(now time.Time) {
+	i := 0
+	for i < len(e.arrivals) && now.Sub(e.arrivals[i]) > e.window {
+		i++
+	}
+	e.arrivals = e.arrivals[i:]
+}
+
+// Shows or hides the possible-incident banner to match the escalation
+// window. Runs on the event loop.
+func (t *tui) updateAlert() {
+	count, active := t.escalation.active(time.Now())
+	if !active {
+		if t.alertView.GetText(false) != "" {
+			t.alertView.Clear()
+			t.layout.ResizeItem(t.alertView, 0, 0)
+		}
+		return
+	}
+
+	text := fmt.Sprintf("[white::b]POSSIBLE ACTIVE INCIDENT — %d High-priority items in the last %v[-::-]", count, t.cfg.EscalateWindow)
+	if t.alertView.GetText(false) == "" {
+		slog.Warn("possible active incident", "high_items", count, "window", t.cfg.EscalateWindow)
+	}
+	t.alertView.SetText(text)
+	t.layout.ResizeItem(t.alertView, 1, 0)
+}
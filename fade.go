@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Fade levels with different color intensities
+var fadeLevels = []string{
+	"[white]", // Newest entry (brightest)
+	"[lightgray]",
+	"[gray]",
+	"[darkgray]",
+	"[black]", // Oldest entry (faded out)
+}
+
+// Formats entries with a fading effect by applying different colors from
+// levels based on their position, newest first. This runs on every redraw,
+// so the output is written into a single builder sized up front instead of
+// joining per-entry strings.
+func formatEntriesWithFade(entries []string, levels []string) string {
+	return writeFaded(entries, levels, func(i int) int {
+		return i * (len(levels) - 1) / len(entries)
+	})
+}
+
+// Like formatEntriesWithFade, but picks each entry's level from its age so a
+// quiet period visibly ages the feed: entries reach the last level once
+// they're span old.
+func formatEntriesWithAgeFade(entries []string, ages []time.Duration, levels []string, span time.Duration) string {
+	return writeFaded(entries, levels, func(i int) int {
+		return min(len(levels)-1, int(int64(ages[i])*int64(len(levels)-1)/int64(span)))
+	})
+}
+
+// Joins entries, each wrapped in the color of the fade level chosen by level
+func writeFaded(entries []string, levels []string, level func(i int) int) string {
+	if len(entries) == 0 {
+		return ""
+	}
+
+	size := (len(entries) - 1) * len("\n\n")
+	for i, entry := range entries {
+		size += len(levels[level(i)]) + len(entry) + len("[-]")
+	}
+
+	var b strings.Builder
+	b.Grow(size)
+	for i, entry := range entries {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(levels[level(i)])
+		b.WriteString(entry)
+		b.WriteString("[-]")
+	}
+
+	return b.String()
+}
+
+// Generates a fade gradient of the given number of steps, interpolating
+// linearly from the start color to the end color. Colors may be tview color
+// names or "#rrggbb" hex values and default to white and black.
+func fadeGradient(start, end string, steps int) ([]string, error) {
+	if start == "" {
+		start = "#ffffff"
+	}
+	if end == "" {
+		end = "#000000"
+	}
+
+	from := tcell.GetColor(strings.ToLower(start))
+	if from == tcell.ColorDefault {
+		return nil, fmt.Errorf("unknown fade color %q", start)
+	}
+	to := tcell.GetColor(strings.ToLower(end))
+	if to == tcell.ColorDefault {
+		return nil, fmt.Errorf("unknown fade color %q", end)
+	}
+
+	// A single step is just the start color
+	if steps == 1 {
+		return []string{fmt.Sprintf("[#%06x]", from.Hex())}, nil
+	}
+
+	r1, g1, b1 := from.RGB()
+	r2, g2, b2 := to.RGB()
+	levels := make([]string, steps)
+	for i := range levels {
+		r := r1 + (r2-r1)*int32(i)/int32(steps-1)
+		g := g1 + (g2-g1)*int32(i)/int32(steps-1)
+		b := b1 + (b2-b1)*int32(i)/int32(steps-1)
+		levels[i] = fmt.Sprintf("[#%02x%02x%02x]", r, g, b)
+	}
+
+	return levels, nil
+}
+
+// Prints each fade level of the theme and fade flags as a sample line in
+// its color, for previewing a gradient with -show-gradient
+func printGradient(w io.Writer, cfg Config) {
+	levels := themes[cfg.Theme].fade(cfg)
+	mode := "by position"
+	if cfg.TimeBasedFade > 0 {
+		mode = fmt.Sprintf("by age, fully faded after %v", cfg.TimeBasedFade)
+	}
+	fmt.Fprintf(w, "Theme %s, %d steps, fading %s:\n", cfg.Theme, len(levels), mode)
+	for i, level := range levels {
+		color := tcell.GetColor(strings.Trim(level, "[]"))
+		r, g, b := color.RGB()
+		fmt.Fprintf(w, "\x1b[38;2;%d;%d;%dm%2d %-12s Priority: High — sample headline\x1b[0m\n", r, g, b, i+1, level)
+	}
+}
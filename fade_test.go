@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Feed entries like the ones a busy cycle brings, newest first
+func sampleFeed(n int) []feedEntry {
+	entries := make([]feedEntry, n)
+	for i := range entries {
+		entries[i] = feedEntry{Insight: HighValueInsight{
+			ID:       hnID(40000000 + i),
+			Title:    fmt.Sprintf("Critical vulnerability %d in widely used library", i),
+			URL:      fmt.Sprintf("https://example.com/advisories/%d", i),
+			Summary:  "Remote code execution affecting default configurations; patch available.",
+			Priority: priorityHigh,
+		}}
+	}
+	return entries
+}
+
+func TestFormatEntriesWithFade(t *testing.T) {
+	levels := []string{"[#ffffff]", "[#808080]", "[#000000]"}
+	got := formatEntriesWithFade([]string{"a", "b", "c"}, levels)
+	// Levels are spread by position, so the oldest entry stops short of the last
+	want := "[#ffffff]a[-]\n\n[#ffffff]b[-]\n\n[#808080]c[-]"
+	if got != want {
+		t.Errorf("formatEntriesWithFade() = %q, want %q", got, want)
+	}
+	if got := formatEntriesWithFade(nil, levels); got != "" {
+		t.Errorf("formatEntriesWithFade(nil) = %q, want empty", got)
+	}
+
+	// More entries than levels share levels, newest brightest
+	got = formatEntriesWithFade(strings.Fields("a b c d e f"), levels)
+	if !strings.HasPrefix(got, "[#ffffff]a[-]") || !strings.HasSuffix(got, "[#808080]f[-]") {
+		t.Errorf("formatEntriesWithFade() = %q, want the first level first and a later level last", got)
+	}
+}
+
+func BenchmarkFormatEntriesWithFade(b *testing.B) {
+	// The default -max-entries, and a long feed
+	cfg := Config{TitleWidth: 80}
+	for _, n := range []int{20, 200} {
+		var entries []string
+		for _, entry := range sampleFeed(n) {
+			entries = append(entries, formatEntry(entry, themes["default"], cfg, false, false))
+		}
+		b.Run(fmt.Sprintf("entries=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				formatEntriesWithFade(entries, fadeLevels)
+			}
+		})
+	}
+}
+
+// The whole per-redraw path: formatting each entry, then fading the feed
+func BenchmarkFormatAndFade(b *testing.B) {
+	cfg := Config{TitleWidth: 80}
+	th := themes["default"]
+	entries := sampleFeed(20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		messages := make([]string, len(entries))
+		for j, entry := range entries {
+			messages[j] = formatEntry(entry, th, cfg, false, false)
+		}
+		formatEntriesWithFade(messages, fadeLevels)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// The display filters Tab cycles through; "" shows every priority
+var priorityFilters = []string{"", priorityHigh, priorityMedium, priorityLow}
+
+// Reports whether the entry passes the priority filters. Errors and notices
+// are hidden while filtering, but a collapsed Low-priority group counts as
+// Low and a digest by its own priority. The caller must hold t.mu.
+func (t *tui) passesPriorityFilter(entry feedEntry) bool {
+	prioritized := entry.isStory() || entry.Insight.Digest
+	if t.minPriority != "" && (!prioritized || priorityRank(entry.Insight.Priority) < priorityRank(t.minPriority)) {
+		return false
+	}
+	switch {
+	case t.priorityFilter == "":
+		return true
+	case entry.Group != nil:
+		return t.priorityFilter == priorityLow
+	case !prioritized:
+		return false
+	}
+	return priorityLevel(entry.Insight.Priority) == t.priorityFilter
+}
+
+// Moves the priority filter step places along All→High→Medium→Low, keeping
+// the selection if it's still shown
+func (t *tui) cyclePriorityFilter(step int) {
+	t.mu.Lock()
+	n := len(priorityFilters)
+	next := priorityFilters[((slices.Index(priorityFilters, t.priorityFilter)+step)%n+n)%n]
+	t.priorityFilter = next
+	t.selectVisible()
+	t.mu.Unlock()
+
+	t.render()
+	if next == "" {
+		t.flash("Showing all priorities")
+	} else {
+		t.flash(fmt.Sprintf("Showing only %s priority", next))
+	}
+}
+
+// The minimum priorities m cycles through; "" shows every priority
+var minPriorities = []string{"", priorityMedium, priorityHigh}
+
+// Moves the minimum priority along All→Medium+→High, keeping the selection
+// if it's still shown
+func (t *tui) cycleMinPriority() {
+	t.mu.Lock()
+	next := minPriorities[(slices.Index(minPriorities, t.minPriority)+1)%len(minPriorities)]
+	t.minPriority = next
+	t.selectVisible()
+	t.mu.Unlock()
+
+	t.render()
+	switch next {
+	case "":
+		t.flash("Showing all priorities")
+	case priorityHigh:
+		t.flash("Showing only High priority")
+	default:
+		t.flash(fmt.Sprintf("Showing %s priority and above", next))
+	}
+}
+
+// Switches between listing the feed newest first and highest priority first
+func (t *tui) toggleSortByPriority() {
+	t.mu.Lock()
+	t.sortByPriority = !t.sortByPriority
+	sorted := t.sortByPriority
+	t.mu.Unlock()
+
+	t.render()
+	if sorted {
+		t.flash("Sorting by priority, then newest first")
+	} else {
+		t.flash("Sorting newest first")
+	}
+}
+
+// Returns the feed pane's title with the active priority filters and sort
+// and the read state, if any. The caller must hold t.mu.
+func (t *tui) feedTitle() string {
+	title := feedTitle(t.cfg)
+	if t.priorityFilter != "" {
+		title += " — " + t.priorityFilter + " only"
+	}
+	switch t.minPriority {
+	case "":
+	case priorityHigh:
+		title += " — High only"
+	default:
+		title += " — " + t.minPriority + "+"
+	}
+	if t.sortByPriority {
+		title += " — by priority"
+	}
+	if summary := t.readSummary(); summary != "" {
+		title += " — " + summary
+	}
+	return title
+}
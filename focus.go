@@ -0,0 +1,18 @@
+package main
+
+// The domain stories are judged for, as worded in the prompts
+type focus struct {
+	Analyst string // Who the model is asked to be, e.g. "cybersecurity analyst"
+	Field   string // What stories are relevant to, e.g. "cybersecurity"
+}
+
+// Built-in focuses, selectable with -focus
+var focuses = map[string]focus{
+	"cybersecurity": {Analyst: "cybersecurity analyst", Field: "cybersecurity"},
+	"finance":       {Analyst: "financial analyst", Field: "finance and markets"},
+	"aviation":      {Analyst: "aviation safety analyst", Field: "aviation"},
+	"tech":          {Analyst: "technology analyst", Field: "the technology industry"},
+}
+
+// The focuses in the order -focus lists them
+var focusNames = []string{"cybersecurity", "finance", "aviation", "tech"}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// Hosts each source's requests go to, for applying its -header values
+var sourceHosts = map[string]string{
+	"hn":       "hacker-news.firebaseio.com",
+	"lobsters": "lobste.rs",
+}
+
+// Parses a -header value of the form "source:Name: value" and adds the
+// header to headers under the source's host. The value is kept out of any
+// error, since headers often carry tokens.
+func addSourceHeader(headers map[string]http.Header, spec string) error {
+	source, header, ok := strings.Cut(spec, ":")
+	host, known := sourceHosts[source]
+	if !ok || !known {
+		return fmt.Errorf("expected source:Name: value with source hn or lobsters")
+	}
+	name, value, err := parseHeader(header)
+	if err != nil {
+		return err
+	}
+
+	if headers[host] == nil {
+		headers[host] = http.Header{}
+	}
+	headers[host].Add(name, value)
+	return nil
+}
+
+// Splits "Name: value" into the canonical header name and the trimmed
+// value, keeping the value out of any error
+func parseHeader(header string) (name, value string, err error) {
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || !validHeaderName(name) {
+		return "", "", fmt.Errorf("invalid header name %q", name)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return "", "", fmt.Errorf("value of header %s contains a line break", name)
+	}
+	return textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(value), nil
+}
+
+// Reports whether name is a valid HTTP header field name (an RFC 7230 token)
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
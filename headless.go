@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Runs the feed without a TUI, printing each entry to stdout, until ctx is
+// done. Used when requested with -headless or when stdout isn't a terminal.
+func runHeadless(ctx context.Context, cfg Config, seen *seenSet) {
+	stop := startPollFeed(ctx, cfg, seen, nil, headlessHandlers(cfg))
+	<-ctx.Done()
+	stop()
+}
+
+// Returns handlers that print each result to stdout
+func headlessHandlers(cfg Config) feedHandlers {
+	return feedHandlers{
+		Insight: func(insight HighValueInsight, err error) {
+			printInsight(cfg, insight, err)
+		},
+		Error: func(err error) {
+			printError(cfg, err)
+		},
+		Skipped: func(story Story, reason string) {
+			printSkipped(cfg, story, reason)
+		},
+	}
+}
+
+// Prints a story dropped by a pre-filter; JSON output leaves it to the log
+func printSkipped(cfg Config, story Story, reason string) {
+	if cfg.JSON {
+		slog.Info("skipped story", "title", story.Title, "reason", reason)
+		return
+	}
+	fmt.Printf("Skipped (%s): %s\n%s\n\n", reason, story.Title, story.URL)
+}
+
+// Prints an analyzed story to stdout as plain text, or as a JSON record per
+// line with -json. In JSON mode failed analyses are logged rather than printed
+// so the output stays machine-readable.
+func printInsight(cfg Config, insight HighValueInsight, err error) {
+	if cfg.JSON {
+		if err != nil {
+			slog.Warn("analysis failed", "title", insight.Title, "err", err)
+			return
+		}
+		data, err := json.Marshal(newInsightRecord(insight))
+		if err != nil {
+			slog.Error("encoding insight failed", "err", err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	summary := insight.Summary
+	if insight.RepeatedSummary {
+		summary = "(same summary as a recent story)"
+	}
+	if err != nil {
+		summary = "Analysis not available"
+	}
+	fmt.Printf("Priority: %s\n%s\n%s\n%s\n\n", insight.Priority, insight.Title, insight.URL, summary)
+}
+
+// Prints a failed fetch cycle to stdout; JSON output leaves it to the log
+func printError(cfg Config, err error) {
+	if cfg.JSON {
+		return
+	}
+	var outage *allSourcesError
+	if errors.As(err, &outage) {
+		fmt.Printf("Error: %v\n%s\n", err, outage.Details())
+		return
+	}
+	fmt.Printf("Error: %v\n\n", err)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Whether each source's most recent fetch succeeded, shown in the status bar
+type healthTracker struct {
+	mu   sync.Mutex
+	down map[string]bool
+}
+
+var sourceHealth = &healthTracker{down: make(map[string]bool)}
+
+// Records the outcome of a fetch from source
+func (h *healthTracker) record(source string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.down[source] = err != nil
+}
+
+// Lists the sources that are currently down, or "" when all are up
+func (h *healthTracker) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var down []string
+	for source, isDown := range h.down {
+		if isDown {
+			down = append(down, source)
+		}
+	}
+	if len(down) == 0 {
+		return ""
+	}
+	sort.Strings(down)
+	return fmt.Sprintf("[red]down: %s[-]  ", strings.Join(down, ", "))
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Shows that the feed is still being monitored when nothing has arrived for
+// -heartbeat. A newer heartbeat replaces one still at the top of the feed
+// rather than pushing real entries out.
+func (t *tui) heartbeat() {
+	t.mu.Lock()
+	if time.Since(t.lastEntryAt) < t.cfg.Heartbeat {
+		t.mu.Unlock()
+		return
+	}
+	notice := fmt.Sprintf("Monitoring — %d stories scanned, 0 new (as of %s)",
+		stats.scanned.Load()-t.scannedAtLatest, time.Now().Format("15:04"))
+	replace := len(t.entries) > 0 && t.entries[0].Notice != ""
+	if replace {
+		t.entries[0].Notice = notice
+	}
+	t.mu.Unlock()
+
+	if replace {
+		t.render()
+	} else {
+		t.add(feedEntry{Notice: notice})
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Client shared by every outbound request; configured at startup from flags
+var httpClient = &http.Client{
+	Transport: &headerTransport{base: http.DefaultTransport},
+}
+
+// Sets the User-Agent header on every request passing through it, plus any
+// -header values for the request's host
+type headerTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	headers   map[string]http.Header // By host
+}
+
+// Accept-Encoding is left unset unless a -header asks for it, which lets the
+// default transport request gzip and decompress responses transparently; it
+// stops doing so once the header is set by hand.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	extra := t.headers[req.URL.Hostname()]
+	if t.userAgent != "" || len(extra) > 0 {
+		// RoundTrippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		if t.userAgent != "" {
+			req.Header.Set("User-Agent", t.userAgent)
+		}
+		for name, values := range extra {
+			req.Header[name] = values
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// Caps the number of requests in flight. A slot is held until the response
+// body is closed, since reading the body still occupies the connection.
+type limitTransport struct {
+	base  http.RoundTripper
+	slots chan struct{}
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		<-t.slots
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() { <-t.slots }}
+	return resp, nil
+}
+
+// Response body that runs release once, on the first Close
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// Issues a GET through the shared client that's abandoned when ctx is done
+func httpGet(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return httpClient.Do(req)
+}
+
+const (
+	fetchAttempts  = 3                      // Tries for each source API request
+	fetchTimeout   = 20 * time.Second       // Limit on each try, so a stalled connection is retried
+	fetchRetryBase = 500 * time.Millisecond // Wait before the second try, doubled for each one after
+)
+
+// Fetches a JSON document from a source API, retrying network errors and
+// overloaded servers with a doubling wait. Returns the body and the final
+// response's headers.
+func fetchJSON(ctx context.Context, url string) ([]byte, http.Header, error) {
+	return fetchBody(ctx, url, readJSONBody)
+}
+
+// Like fetchJSON, but reads each response with read
+func fetchBody(ctx context.Context, url string, read func(*http.Response) ([]byte, error)) ([]byte, http.Header, error) {
+	var err error
+	for attempt := 0; attempt < fetchAttempts; attempt++ {
+		if attempt > 0 {
+			slog.Debug("retrying request", "url", url, "attempt", attempt+1, "err", err)
+			select {
+			case <-time.After(fetchRetryBase << (attempt - 1)):
+			case <-ctx.Done():
+				return nil, nil, err
+			}
+		}
+
+		var body []byte
+		var header http.Header
+		body, header, err = fetchOnce(ctx, url, read)
+		if err == nil || !isTransient(err) || ctx.Err() != nil {
+			return body, header, err
+		}
+	}
+	return nil, nil, err
+}
+
+func fetchOnce(ctx context.Context, url string, read func(*http.Response) ([]byte, error)) ([]byte, http.Header, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	resp, err := httpGet(ctx, url)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := read(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, resp.Header, nil
+}
+
+// Loads a PEM bundle on top of the system roots, failing if the file holds
+// no certificates
+func loadCACert(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: no PEM certificates found", path)
+	}
+	return pool, nil
+}
+
+// Applies the HTTP-related flags to the shared client
+func configureHTTPClient(cfg Config) {
+	var base http.RoundTripper = http.DefaultTransport
+	if cfg.RootCAs != nil || cfg.InsecureSkipVerify {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:            cfg.RootCAs,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+		}
+		base = transport
+	}
+
+	httpClient.Transport = &headerTransport{
+		base: &limitTransport{
+			base:  base,
+			slots: make(chan struct{}, cfg.MaxConcurrent),
+		},
+		userAgent: cfg.UserAgent,
+		headers:   cfg.Headers,
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Applies cfg to the shared client for the rest of the test
+func configureTestClient(t *testing.T, cfg Config) {
+	t.Helper()
+	saved := httpClient.Transport
+	t.Cleanup(func() { httpClient.Transport = saved })
+	if cfg.MaxConcurrent == 0 {
+		cfg.MaxConcurrent = 8
+	}
+	configureHTTPClient(cfg)
+}
+
+func TestFetchJSONRejectsNonJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		status      int
+		want        string
+	}{
+		{"HTML error page", "text/html; charset=utf-8", http.StatusOK, "expected JSON, got text/html; charset=utf-8 (status 200)"},
+		{"HTML not found", "text/html", http.StatusNotFound, "expected JSON, got text/html (status 404)"},
+		{"no content type", "", http.StatusOK, "expected JSON, got no Content-Type (status 200)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Set explicitly, or an empty type would be sniffed as HTML
+				w.Header()["Content-Type"] = []string{tt.contentType}
+				w.WriteHeader(tt.status)
+				w.Write([]byte("<html><body>Service Unavailable</body></html>"))
+			}))
+			defer srv.Close()
+
+			_, _, err := fetchJSON(context.Background(), srv.URL)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("fetchJSON() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchJSONAcceptsJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`[1,2,3]`))
+	}))
+	defer srv.Close()
+
+	body, _, err := fetchJSON(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("fetchJSON() error = %v", err)
+	}
+	if string(body) != "[1,2,3]" {
+		t.Errorf("fetchJSON() body = %q, want %q", body, "[1,2,3]")
+	}
+}
+
+func TestUserAgentSentOnEveryRequest(t *testing.T) {
+	agents := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents <- r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	configureTestClient(t, Config{UserAgent: "intelstream-test/1.0"})
+
+	// Source fetches and model requests both go through the shared client
+	if _, _, err := fetchJSON(context.Background(), srv.URL); err != nil {
+		t.Fatalf("fetchJSON() error = %v", err)
+	}
+	if _, err := queryOllama(context.Background(), srv.URL, "llama3.2", "prompt"); err != nil {
+		t.Fatalf("queryOllama() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if got := <-agents; got != "intelstream-test/1.0" {
+			t.Errorf("request %d User-Agent = %q, want %q", i+1, got, "intelstream-test/1.0")
+		}
+	}
+}
+
+func TestMaxConcurrentBoundsInFlightRequests(t *testing.T) {
+	const limit = 2
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	configureTestClient(t, Config{MaxConcurrent: limit})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := fetchJSON(context.Background(), srv.URL); err != nil {
+				t.Errorf("fetchJSON() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > limit {
+		t.Errorf("peak in-flight requests = %d, want at most %d", got, limit)
+	}
+}
+
+func TestFetchJSONDecompressesGzip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("Accept-Encoding = %q, want gzip offered", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`[8863,8864]`))
+		gz.Close()
+	}))
+	defer srv.Close()
+
+	// Through the full transport chain, with a -header for the host too
+	configureTestClient(t, Config{
+		UserAgent: "intelstream-test/1.0",
+		Headers:   map[string]http.Header{"127.0.0.1": {"Authorization": {"Bearer token"}}},
+	})
+	body, _, err := fetchJSON(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("fetchJSON() error = %v", err)
+	}
+	if string(body) != "[8863,8864]" {
+		t.Errorf("fetchJSON() body = %q, want the decompressed %q", body, "[8863,8864]")
+	}
+}
+
+func TestFetchRejectsOversizedBody(t *testing.T) {
+	saved := maxBodyBytes
+	t.Cleanup(func() { maxBodyBytes = saved })
+	maxBodyBytes = 64
+
+	tests := []struct {
+		name    string
+		size    int
+		read    func(*http.Response) ([]byte, error)
+		wantErr bool
+	}{
+		{"JSON at the cap", 64, readJSONBody, false},
+		{"JSON over the cap", 65, readJSONBody, true},
+		{"JSON far over the cap", 1 << 20, readJSONBody, true},
+		{"feed at the cap", 64, readFeedBody, false},
+		{"feed over the cap", 65, readFeedBody, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`"` + strings.Repeat("x", tt.size-2) + `"`))
+			}))
+			defer srv.Close()
+
+			body, _, err := fetchBody(context.Background(), srv.URL, tt.read)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "response too large") {
+					t.Errorf("fetchBody() error = %v, want a response too large error", err)
+				}
+				return
+			}
+			if err != nil || len(body) != tt.size {
+				t.Errorf("fetchBody() = %d bytes, %v; want %d bytes", len(body), err, tt.size)
+			}
+		})
+	}
+}
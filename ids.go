@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Analyzes the HN items listed in cfg.IDsFile and prints the results like
+// headless mode. Listed items are analyzed even if they were seen before;
+// items that aren't stories are skipped with a warning.
+func runIDsFile(ctx context.Context, cfg Config) error {
+	ids, err := readIDsFile(cfg.IDsFile)
+	if err != nil {
+		return err
+	}
+
+	var stories []Story
+	for start := 0; start < len(ids); start += cfg.FetchWorkers {
+		batch := ids[start:min(start+cfg.FetchWorkers, len(ids))]
+		details, errs := fetchStoryBatch(ctx, batch)
+		for i, id := range batch {
+			story, err := details[i], errs[i]
+			switch {
+			case err != nil:
+				slog.Warn("skipping item", "id", id, "err", err)
+			case story.Type != "" && story.Type != "story":
+				slog.Warn("skipping item", "id", id, "err", fmt.Sprintf("item is a %s, not a story", story.Type))
+			default:
+				stories = append(stories, story)
+			}
+		}
+	}
+
+	analyzeAll(ctx, cfg, stories, headlessHandlers(cfg))
+	return nil
+}
+
+// Reads HN item IDs separated by whitespace or commas. Lines starting with #
+// are comments.
+func readIDsFile(path string) ([]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for lineNo, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\r' }) {
+			id, err := strconv.Atoi(field)
+			if err != nil || id <= 0 {
+				return nil, fmt.Errorf("%s:%d: invalid item ID %q", path, lineNo+1, field)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// A keyboard shortcut in the feed and the action it triggers. Every shortcut
+// is registered here so the help overlay always lists the current set.
+type keyBinding struct {
+	Keys        []tcell.Key // Special keys that trigger the binding
+	Runes       []rune      // Printable keys that trigger the binding
+	Label       string      // How the keys are shown in the help overlay
+	Description string
+	Action      func()
+}
+
+// Registers the feed's keyboard shortcuts
+func (t *tui) registerKeys() {
+	t.bindings = []keyBinding{
+		{Keys: []tcell.Key{tcell.KeyDown}, Runes: []rune{'j'}, Label: "j/↓", Description: "Select next entry", Action: func() { t.moveSelection(1) }},
+		{Keys: []tcell.Key{tcell.KeyUp}, Runes: []rune{'k'}, Label: "k/↑", Description: "Select previous entry", Action: func() { t.moveSelection(-1) }},
+		{Keys: []tcell.Key{tcell.KeyEnter}, Label: "Enter", Description: "Show details of selected entry", Action: t.showDetail},
+		{Runes: []rune{'y'}, Label: "y", Description: "Copy selected URL to clipboard", Action: t.copySelectedURL},
+		{Runes: []rune{'o'}, Label: "o", Description: "Open selected article in browser", Action: func() { t.openSelected(false) }},
+		{Runes: []rune{'d'}, Label: "d", Description: "Open selected story's discussion", Action: func() { t.openSelected(true) }},
+		{Runes: []rune{'s'}, Label: "s", Description: "Snooze selected entry", Action: t.snoozeSelected},
+		{Runes: []rune{'x'}, Label: "x", Description: "Mark read, or expand a Low group", Action: t.toggleReadSelected},
+		{Runes: []rune{'X'}, Label: "X", Description: "Hide or show read entries", Action: t.toggleHideRead},
+		{Runes: []rune{'r'}, Label: "r", Description: "Refresh the feed now", Action: t.refreshNow},
+		{Runes: []rune{'R'}, Label: "R", Description: "Re-analyze selected story", Action: t.reanalyzeSelected},
+		{Runes: []rune{'1'}, Label: "1/2/3", Description: "Set selected priority High/Medium/Low", Action: func() { t.overridePriority(priorityHigh) }},
+		{Runes: []rune{'2'}, Label: "", Action: func() { t.overridePriority(priorityMedium) }},
+		{Runes: []rune{'3'}, Label: "", Action: func() { t.overridePriority(priorityLow) }},
+		{Runes: []rune{'P'}, Label: "P", Description: "Pause or resume analysis", Action: t.togglePauseAnalysis},
+		{Runes: []rune{'A'}, Label: "A", Description: "Re-analyze all displayed stories", Action: t.reanalyzeAll},
+		{Runes: []rune{'t'}, Label: "t", Description: "Tag selected entry with an incident", Action: t.tagSelected},
+		{Runes: []rune{'f'}, Label: "f", Description: "Filter feed to an incident tag", Action: t.filterByTag},
+		{Keys: []tcell.Key{tcell.KeyTab}, Label: "Tab/⇧Tab", Description: "Cycle priority filter", Action: func() { t.cyclePriorityFilter(1) }},
+		{Keys: []tcell.Key{tcell.KeyBacktab}, Label: "", Action: func() { t.cyclePriorityFilter(-1) }},
+		{Runes: []rune{'m'}, Label: "m", Description: "Cycle minimum priority shown", Action: t.cycleMinPriority},
+		{Runes: []rune{'h'}, Label: "h", Description: "Toggle sorting by priority", Action: t.toggleSortByPriority},
+		{Runes: []rune{'c'}, Label: "c", Description: "Clear the feed", Action: t.clearFeed},
+		{Runes: []rune{'S'}, Label: "S", Description: "Toggle summaries (compact view)", Action: t.toggleSummaries},
+		{Runes: []rune{'u'}, Label: "u", Description: "Toggle URL lines", Action: t.toggleURLs},
+		{Runes: []rune{'G'}, Label: "G", Description: "Toggle grouping feed by source", Action: t.toggleGrouped},
+		{Runes: []rune{'z'}, Label: "z", Description: "Collapse selected entry's source", Action: t.collapseSection},
+		{Runes: []rune{'Z'}, Label: "Z", Description: "Expand all source sections", Action: t.expandSections},
+		{Runes: []rune{'L'}, Label: "L", Description: "Toggle the log pane", Action: t.toggleLog},
+		{Runes: []rune{'T'}, Label: "T", Description: "Cycle color theme", Action: t.cycleTheme},
+		{Runes: []rune{'?'}, Label: "?", Description: "Toggle this help", Action: t.toggleHelp},
+		{Runes: []rune{'q'}, Label: "q", Description: "Quit", Action: t.app.Stop},
+	}
+}
+
+// Dispatches a key event in the feed to its registered binding
+func (t *tui) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	for _, binding := range t.bindings {
+		if binding.matches(event) {
+			binding.Action()
+			return nil
+		}
+	}
+	return event
+}
+
+// Reports whether the event triggers this binding
+func (b keyBinding) matches(event *tcell.EventKey) bool {
+	if event.Key() == tcell.KeyRune {
+		for _, r := range b.Runes {
+			if event.Rune() == r {
+				return true
+			}
+		}
+		return false
+	}
+	for _, key := range b.Keys {
+		if event.Key() == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Builds the help overlay listing every registered binding
+func (t *tui) newHelpView() tview.Primitive {
+	var lines []string
+	for _, binding := range t.bindings {
+		if binding.Label == "" {
+			continue // Listed under a neighboring binding's label
+		}
+		lines = append(lines, fmt.Sprintf("[yellow]%-8s[-] %s", binding.Label, binding.Description))
+	}
+	lines = append(lines, "", "[gray]Esc or ? to close[-]")
+
+	helpView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(strings.Join(lines, "\n"))
+	helpView.SetBorder(true).SetTitle("Keyboard Shortcuts")
+	helpView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == '?' {
+			t.toggleHelp()
+			return nil
+		}
+		return event
+	})
+
+	return centered(helpView, 50, len(lines)+2)
+}
+
+// Shows or hides the help overlay
+func (t *tui) toggleHelp() {
+	if t.pages.HasPage("help") {
+		t.pages.RemovePage("help")
+		return
+	}
+	t.pages.AddPage("help", t.newHelpView(), true, true)
+}
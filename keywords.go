@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Loads a keyword weight map from a file of "keyword=weight" pairs separated
+// by commas or newlines. Blank lines and lines starting with # are ignored.
+func loadKeywordWeights(path string) (map[string]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[string]int)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, pair := range strings.Split(line, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			keyword, weight, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("%s: expected keyword=weight, got %q", path, pair)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(weight))
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid weight for %q: %v", path, keyword, err)
+			}
+			weights[strings.ToLower(strings.TrimSpace(keyword))] = n
+		}
+	}
+
+	return weights, nil
+}
+
+// Scores a title by summing the weights of every keyword it contains,
+// ignoring case
+func scoreTitle(title string, weights map[string]int) int {
+	title = strings.ToLower(title)
+	score := 0
+	for keyword, weight := range weights {
+		if strings.Contains(title, keyword) {
+			score += weight
+		}
+	}
+	return score
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Lobste.rs asks API clients to keep their request rate low, so the newest
+// list is fetched at most this often however fast the feed polls
+const lobstersMinInterval = time.Minute
+
+// When Lobste.rs was last fetched; only touched by the poll loop
+var lastLobstersFetch time.Time
+
+// A story as returned by the Lobste.rs JSON API
+type lobstersStory struct {
+	ShortID      string    `json:"short_id"`
+	Title        string    `json:"title"`
+	URL          string    `json:"url"`
+	CommentsURL  string    `json:"comments_url"`
+	Score        int       `json:"score"`
+	CommentCount int       `json:"comment_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Fetches the newest Lobste.rs stories, with IDs made from each story's
+// short_id so seen-story tracking dedupes them
+func fetchLobsters(ctx context.Context) ([]Story, error) {
+	body, _, err := fetchJSON(ctx, "https://lobste.rs/newest.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var items []lobstersStory
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, err
+	}
+
+	stories := make([]Story, 0, len(items))
+	for _, item := range items {
+		if item.ShortID == "" || item.Title == "" {
+			continue
+		}
+		// Text posts have no URL of their own
+		url := item.URL
+		if url == "" {
+			url = item.CommentsURL
+		}
+		// Without a created_at, Time stays 0 so the age checks pass the story
+		// over rather than judge it by the year 1
+		var created int64
+		if !item.CreatedAt.IsZero() {
+			created = item.CreatedAt.Unix()
+		}
+		stories = append(stories, Story{
+			ID:          sourceID("lobsters", item.ShortID),
+			Title:       item.Title,
+			URL:         url,
+			Time:        created,
+			Score:       item.Score,
+			Descendants: item.CommentCount,
+
+			DiscussionURL: item.CommentsURL,
+		})
+	}
+	return stories, nil
+}
+
+// Reports whether lobstersMinInterval has passed since the last fetch
+func lobstersDue() bool {
+	return time.Since(lastLobstersFetch) >= lobstersMinInterval
+}
+
+// Returns up to limit Lobste.rs stories that haven't been seen, applying the
+// same repost and age checks as HN stories. Callers check lobstersDue first.
+func fetchNewLobsters(ctx context.Context, cfg Config, seen *seenSet, limit int, skip func(Story, string)) ([]Story, error) {
+	lastLobstersFetch = time.Now()
+
+	all, err := fetchLobsters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return takeUnseen(cfg, seen, all, limit, skip), nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	logFileQueueSize = 1000             // Insights waiting to be written before new ones are dropped
+	logFileDrainTime = 10 * time.Second // Longest Close waits for queued writes
+)
+
+// A -log-file line: the insight's JSON record with when it was logged and
+// whether the model analyzed it, so headline-only entries are easy to
+// filter out
+type logRecord struct {
+	LoggedAt time.Time `json:"logged_at"`
+	Analyzed bool      `json:"analyzed"`
+	insightRecord
+}
+
+// Appends each insight to a JSONL history file. Writes run in the background
+// so a slow disk never holds up the feed; Emit only queues.
+type logFileSink struct {
+	f     *os.File
+	queue chan logRecord
+	done  chan struct{} // Closed once run has written everything queued
+
+	mu     sync.Mutex // Guards queue against sends after Close
+	closed bool
+}
+
+// Opens path for appending, so restarts add to the history
+func openLogFile(path string) (*logFileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("-log-file: %v", err)
+	}
+	s := &logFileSink{
+		f:     f,
+		queue: make(chan logRecord, logFileQueueSize),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *logFileSink) Emit(insight HighValueInsight) error {
+	record := logRecord{LoggedAt: time.Now().UTC(), Analyzed: !insight.Unanalyzed, insightRecord: newInsightRecord(insight)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return fmt.Errorf("log file closed, dropping story %s", insight.ID)
+	}
+	select {
+	case s.queue <- record:
+		return nil
+	default:
+		return fmt.Errorf("write queue full, dropping story %s", insight.ID)
+	}
+}
+
+// Writes queued records one line at a time. The file is unbuffered, so each
+// line reaches the OS as soon as it's written.
+func (s *logFileSink) run() {
+	defer close(s.done)
+	for record := range s.queue {
+		data, err := json.Marshal(record)
+		if err != nil {
+			slog.Error("log file record failed", "id", record.ID, "err", err)
+			continue
+		}
+		if _, err := s.f.Write(append(data, '\n')); err != nil {
+			slog.Error("log file write failed", "file", s.f.Name(), "id", record.ID, "err", err)
+		}
+	}
+}
+
+// Stops accepting insights, writes what's queued and closes the file. Emit
+// calls after Close fail, as with the webhook sink.
+func (s *logFileSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+	select {
+	case <-s.done:
+	case <-time.After(logFileDrainTime):
+		return fmt.Errorf("gave up waiting for %d queued writes", len(s.queue))
+	}
+	return s.f.Close()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogFileAppendsRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	// Two runs append to the same file
+	for run, insight := range []HighValueInsight{
+		{ID: hnID(1), Title: "Analyzed", Priority: priorityHigh},
+		{ID: hnID(2), Title: "Headline only", Priority: "N/A", Unanalyzed: true},
+	} {
+		s, err := openLogFile(path)
+		if err != nil {
+			t.Fatalf("run %d: openLogFile() error = %v", run+1, err)
+		}
+		if err := s.Emit(insight); err != nil {
+			t.Fatalf("run %d: Emit() error = %v", run+1, err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("run %d: Close() error = %v", run+1, err)
+		}
+		if err := s.Emit(insight); err == nil {
+			t.Errorf("run %d: Emit() after Close: error = nil, want an error", run+1)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log file has %d lines, want 2:\n%s", len(lines), data)
+	}
+	for i, want := range []struct {
+		id       storyID
+		analyzed bool
+	}{{"1", true}, {"2", false}} {
+		var record struct {
+			LoggedAt string  `json:"logged_at"`
+			Analyzed bool    `json:"analyzed"`
+			ID       storyID `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(lines[i]), &record); err != nil {
+			t.Fatalf("line %d: %v", i+1, err)
+		}
+		if record.ID != want.id || record.Analyzed != want.analyzed || record.LoggedAt == "" {
+			t.Errorf("line %d = %+v, want id %q, analyzed %v and a timestamp", i+1, record, want.id, want.analyzed)
+		}
+	}
+}
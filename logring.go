@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// Number of log lines kept for the TUI's log pane
+const logRingSize = 200
+
+// io.Writer keeping the last few log lines in memory; slog writes one
+// record per call
+type logRing struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+var recentLogs = &logRing{}
+
+func (r *logRing) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		r.lines = append(r.lines, line)
+	}
+	if over := len(r.lines) - logRingSize; over > 0 {
+		r.lines = append(r.lines[:0], r.lines[over:]...)
+	}
+	return len(p), nil
+}
+
+// Returns the buffered lines, oldest first
+func (r *logRing) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.lines, "\n")
+}
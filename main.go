@@ -1,213 +1,1044 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"mime"
 	"net/http"
-	"os/exec"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
-	"github.com/rivo/tview"
+	"golang.org/x/term"
 )
 
 type Story struct {
-	Title string `json:"title"`
-	URL   string `json:"url"`
+	ID    storyID `json:"id"`
+	Title string  `json:"title"`
+	URL   string  `json:"url"`
+	Time  int64   `json:"time"` // Unix timestamp of submission
+	Score int     `json:"score"`
+
+	Descendants int    `json:"descendants"` // Total comment count
+	Type        string `json:"type"`        // HN item type: "story", "job", "comment", ...
+
+	DiscussionURL string `json:"-"` // Comments page on the source site, "" when unknown
 }
 
 type HighValueInsight struct {
-	Title    string
-	URL      string
-	Summary  string
-	Priority string
-}
+	ID         storyID `json:"id"` // ID of the analyzed story, "" for stories from no source
+	Title      string  `json:"title"`
+	URL        string  `json:"url"` // The article, or the discussion for text posts
+	Summary    string  `json:"summary"`
+	Priority   string  `json:"priority"`
+	Rationale  string  `json:"rationale,omitempty"`  // Model's one-sentence reason for the priority
+	Confidence float64 `json:"confidence,omitempty"` // Model's confidence in the priority, 0–1
+	Raw        string  `json:"raw,omitempty"`        // Unparsed model output, kept with -keep-raw
+	Tag        string  `json:"tag,omitempty"`        // Incident tag assigned by the analyst
 
-const (
-	maxEntries      = 20 // Maximum number of entries to display
-	numStoriesFetch = 1  // Number of stories to fetch each time
-)
+	DiscussionURL  string `json:"discussion_url,omitempty"`  // Comments page on the source site
+	Rule           string `json:"rule,omitempty"`            // -rules entry that overrode the model's priority
+	OverriddenFrom string `json:"overridden_from,omitempty"` // Priority before the analyst overrode it
 
-// Fade levels with different color intensities
-var fadeLevels = []string{
-	"[white]", // Newest entry (brightest)
-	"[lightgray]",
-	"[gray]",
-	"[darkgray]",
-	"[black]", // Oldest entry (faded out)
+	AnalysisDuration time.Duration `json:"-"` // How long the model took; not part of JSON records
+	RepeatedSummary  bool          `json:"-"` // Summary matches one of the last -summary-window
+	Unanalyzed       bool          `json:"-"` // Headline shown without running the model
+	Digest           bool          `json:"-"` // A -digest summary of a cycle rather than one story
 }
 
+// Model the stories are analyzed with; set from -model at startup
+var analysisModel = "llama3.2"
+
+// Longest one model request may take; set from -ollama-timeout at startup
+var ollamaTimeout time.Duration
+
+// Version reported in the default User-Agent
+const version = "0.1.0"
+
+// Most new stories -fetch-count may ask for in one cycle
+const maxFetchCount = 100
+
+// Base URL of the Hacker News API; set from -api-base at startup
+var hnAPIBase = "https://hacker-news.firebaseio.com/v0"
+
 func main() {
-	app := tview.NewApplication()
-
-	// Create a TextView for the scrolling feed
-	feedView := tview.NewTextView().
-		SetDynamicColors(true).
-		SetScrollable(true).
-		SetWrap(true).
-		SetChangedFunc(func() {
-			app.Draw()
-		})
+	cfg, err := parseConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if !cfg.ValidateConfig {
+			flag.Usage()
+		}
+		os.Exit(2)
+	}
 
-	feedView.SetBorder(true).SetTitle("High-Value Intelligence Feed")
+	if cfg.ShowGradient {
+		printGradient(os.Stdout, cfg)
+		return
+	}
 
-	// List to store entries and track seen stories
-	var entries []string
-	seenStoryIDs := make(map[int]bool)
+	if cfg.ValidateConfig {
+		if err := printEffectiveConfig(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Function to periodically fetch, analyze, and update the feed
-	go func() {
-		for {
-			stories, err := fetchTopStories(seenStoryIDs)
-			if err != nil {
-				entries = addEntry(entries, fmt.Sprintf("[red]Error: %v[-]", err))
-			} else {
-				for _, story := range stories {
-					// Use Ollama to determine if this story is high-value
-					insight, err := analyzeWithOllama(story)
-					if err != nil {
-						insight.Summary = "[red]Analysis not available[-]"
-					}
-					message := fmt.Sprintf("[yellow]Priority: %s[-]\n[green]%s[-]\n%s\n%s",
-						insight.Priority, insight.Title, insight.URL, insight.Summary)
-					entries = addEntry(entries, message)
+	storyCache.ttl = cfg.ItemCacheTTL
+	maxBodyBytes = cfg.MaxBodyBytes
+	recentSummaries.size = cfg.SummaryWindow
+	recentTitles.window = cfg.RepostWindow
+	pausedBacklog.max = cfg.PauseBacklog
+	backendMode = cfg.OnBackendDown
+	setOllamaEndpoints(cfg.OllamaURLs)
+	analysisModel, ollamaTimeout = cfg.Model, cfg.OllamaTimeout
+	hnAPIBase = strings.TrimRight(cfg.APIBase, "/")
+	storyFields.title, storyFields.url = cfg.TitleField, cfg.URLField
+	configureHTTPClient(cfg)
+	if cfg.InsecureSkipVerify {
+		fmt.Fprintln(os.Stderr, "WARNING: -insecure-skip-verify is set; HTTPS certificates are NOT verified and connections can be intercepted")
+	}
+
+	if cfg.ListModels {
+		models, err := listModels(context.Background(), cfg.OllamaURL)
+		if err == nil {
+			err = printModels(os.Stdout, models, cfg.JSON)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if cfg.Sinks, err = openSinks(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Without a terminal tview can't initialize, so fall back to headless mode
+	if !cfg.Headless && !cfg.Once() && !term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Fprintln(os.Stderr, "Warning: stdout is not a terminal, falling back to headless mode")
+		cfg.Headless = true
+	}
+
+	// Diagnostics would garble the TUI, so only log to stderr without it
+	logOutput := io.Discard
+	if cfg.Headless || cfg.Once() {
+		logOutput = os.Stderr
+	}
+	if cfg.LogOutput != "" {
+		f, err := os.OpenFile(cfg.LogOutput, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		logOutput = f
+	}
+	if !cfg.Headless && !cfg.Once() {
+		// Keep recent lines for the TUI's log pane
+		logOutput = io.MultiWriter(logOutput, recentLogs)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(logOutput, nil)))
+
+	// Pick up where the last run left off so old stories don't flood the feed
+	seen := newSeenSet(maxSeenStories)
+	if cfg.SeenFile != "" {
+		seen, err = loadSeenSet(cfg.SeenFile, maxSeenStories)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring seen-story file %s: %v\n", cfg.SeenFile, err)
+		}
+	}
+
+	// Interrupts cancel the in-flight HN and Ollama HTTP requests
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.MinOllamaVersion != "" {
+		for _, ollamaURL := range cfg.OllamaURLs {
+			if err := checkServerVersion(ctx, ollamaURL, cfg.MinOllamaVersion); err != nil {
+				if cfg.StrictVersion {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
 				}
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	}
+
+	if cfg.OnBackendDown != "" {
+		if err := checkBackends(ctx, cfg.OllamaURLs); err != nil {
+			if cfg.OnBackendDown == "exit" {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			slog.Warn("Ollama unavailable at startup, showing headlines only", "err", err, "mode", cfg.OnBackendDown)
+			backendDown.Store(true)
+			if cfg.OnBackendDown == "retry" {
+				go probeBackend(ctx, cfg.OllamaURLs)
+			}
+		}
+	}
+
+	if cfg.AnalyzeURL != "" {
+		if err := runAnalyzeURL(ctx, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if cfg.IDsFile != "" {
+		if err := runIDsFile(ctx, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if cfg.Stdin {
+		if err := runStdin(ctx, cfg, os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if cfg.Once() {
+		if err := runOnce(ctx, cfg, seen); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if cfg.Headless {
+		runHeadless(ctx, cfg, seen)
+	} else if err := runTUI(ctx, cfg, seen); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if c, ok := cfg.Sinks.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if cfg.SeenFile != "" {
+		if err := seen.save(cfg.SeenFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save seen stories: %v\n", err)
+		}
+	}
+}
+
+// Callbacks through which the poll loop reports its results
+type feedHandlers struct {
+	CycleStart func()                        // Called before each cycle; may be nil
+	Insight    func(HighValueInsight, error) // Called for each analyzed story
+	Error      func(error)                   // Called when a cycle's fetch fails
+	Skipped    func(Story, string)           // Called with stories filtered out and why, under -show-skipped; may be nil
+	Repost     func(Story, storyID)          // Called with reposts merged into the story with the given ID; nil skips them
+}
+
+// Reports an analysis result, sending successful ones to any -sink as well
+func (h feedHandlers) insight(cfg Config, insight HighValueInsight, err error) {
+	if err == nil {
+		insight.RepeatedSummary = recentSummaries.repeated(insight.Summary)
+	}
+	if err == nil && cfg.Sinks != nil {
+		cfg.Sinks.Emit(insight) // Failures are logged per sink
+	}
+	h.Insight(insight, err)
+}
+
+// Reports a filtered-out story if -show-skipped asked for them
+func (h feedHandlers) skip(cfg Config, story Story, reason string) {
+	if cfg.ShowSkipped && h.Skipped != nil {
+		h.Skipped(story, reason)
+	}
+}
+
+// Fetches and analyzes new stories until ctx is done, reporting results
+// through h. Seen stories are tracked in seen so they're only analyzed once.
+// A value on refresh starts the next cycle right away; refresh may be nil.
+func pollFeed(ctx context.Context, cfg Config, seen *seenSet, refresh <-chan struct{}, h feedHandlers) {
+	if cfg.Warmup {
+		warmUpModel(ctx)
+	}
+
+	// An -interval above maxBackoff is the cap itself, so failures never poll faster
+	b := backoff{base: cfg.Interval, max: max(maxBackoff, cfg.Interval), after: backoffAfter}
+	for first := true; ; first = false {
+		// Let snoozed stories resurface once their snooze is up
+		seen.expireSnoozes(time.Now())
+
+		limit := cfg.FetchCount
+		if cfg.Digest {
+			limit = cfg.DigestSize
+		} else if first && cfg.Backfill > limit {
+			// Fill the feed on launch rather than a story per cycle
+			limit = cfg.Backfill
+		}
+		err := safeCycle(ctx, cfg, seen, limit, h)
+		if ctx.Err() != nil {
+			return
+		}
+		if cfg.SeenFile != "" {
+			if err := seen.saveIfChanged(cfg.SeenFile); err != nil {
+				slog.Warn("failed to save seen stories", "err", err)
 			}
+		}
 
-			// Update the TextView with the faded entries list
-			feedView.SetText(formatEntriesWithFade(entries))
+		// Wait before fetching again, longer while the upstream keeps failing
+		wait := b.next(err == nil)
+		if wait > cfg.Interval {
+			stats.backoff.Store(int64(wait))
+		} else {
+			stats.backoff.Store(0)
+		}
+		timer := time.NewTimer(jitter(wait, cfg.Jitter))
+		select {
+		case <-timer.C:
+		case <-refresh:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
 
-			// Wait before fetching again
-			time.Sleep(5 * time.Second) // Adjust interval as needed
+// Runs one cycle, turning a panic into a reported error so a bug hit by one
+// story or response doesn't stop the feed; the poll loop then carries on as
+// after any failed cycle
+func safeCycle(ctx context.Context, cfg Config, seen *seenSet, limit int, h feedHandlers) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error: %v", r)
+			slog.Error("cycle panicked", "panic", r, "stack", string(debug.Stack()))
+			stats.errors.Add(1)
+			h.Error(err)
 		}
 	}()
+	return runCycle(ctx, cfg, seen, limit, h)
+}
+
+// How long shutdown waits for the poll loop to return once its in-flight
+// HTTP requests are cancelled
+const shutdownTimeout = 5 * time.Second
+
+// Runs pollFeed in the background. The returned function cancels it and
+// waits, up to shutdownTimeout, for it to return.
+func startPollFeed(ctx context.Context, cfg Config, seen *seenSet, refresh <-chan struct{}, h feedHandlers) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pollFeed(ctx, cfg, seen, refresh, h)
+	}()
 
-	// Set up and run the app
-	if err := app.SetRoot(feedView, true).EnableMouse(true).Run(); err != nil {
-		panic(err)
+	return func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(shutdownTimeout):
+			slog.Warn("poll loop didn't stop in time", "timeout", shutdownTimeout)
+		}
 	}
 }
 
-// Adds a new entry to the top of the list and keeps the most recent maxEntries entries
-func addEntry(entries []string, message string) []string {
-	// Add the new message to the top of the list
-	entries = append([]string{message}, entries...)
+// Runs a single fetch-and-analyze cycle over up to limit new stories,
+// returning the HN fetch error if that failed
+func runCycle(ctx context.Context, cfg Config, seen *seenSet, limit int, h feedHandlers) error {
+	if h.CycleStart != nil {
+		h.CycleStart()
+	}
+
+	if cfg.Digest {
+		limit = min(limit, cfg.DigestSize)
+	}
+
+	if cfg.CycleTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.CycleTimeout)
+		defer cancel()
+	}
+
+	skip := func(story Story, reason string) { h.skip(cfg, story, reason) }
+	var (
+		stories   []Story
+		err       error
+		attempted int
+		failures  []fetchError
+	)
+	for i, source := range cfg.Sources {
+		if !source.Due() {
+			continue
+		}
+		more, serr := source.Fetch(ctx, cfg, seen, limit, skip)
+		sourceHealth.record(source.Name(), serr)
+		attempted++
+		if serr != nil {
+			failures = append(failures, fetchError{source.Name(), serr})
+			// Failures of the other sources are reported but don't fail the
+			// cycle, so HN stories are still analyzed and backoff only
+			// tracks HN
+			if i == 0 {
+				err = serr
+			}
+		}
+		stories = append(stories, more...)
+	}
+
+	// A total outage is one calm entry rather than one per source
+	if len(failures) > 1 && len(failures) == attempted {
+		reportFetchError(cfg, &allSourcesError{failures}, h)
+	} else {
+		for _, f := range failures {
+			if attempted == 1 {
+				reportFetchError(cfg, f, h)
+			} else {
+				reportFetchError(cfg, fmt.Errorf("%s: %w", strings.ToLower(f.Source), f), h)
+			}
+		}
+	}
 
-	// If the list exceeds the maximum number of entries, remove the oldest one
-	if len(entries) > maxEntries {
-		entries = entries[:maxEntries]
+	stories = filterReposts(cfg, stories, h)
+	orderStories(stories, cfg.AnalyzeOrder)
+	if cfg.Digest {
+		stories = slices.DeleteFunc(stories, func(story Story) bool {
+			reason := titleFilter(cfg, story)
+			if reason != "" {
+				stats.preFiltered.Add(1)
+				h.skip(cfg, story, reason)
+			}
+			return reason != ""
+		})
+		if len(stories) > 0 {
+			insight, derr := digestWithOllama(ctx, cfg, stories)
+			h.insight(cfg, insight, derr)
+		}
+		return err
+	}
+	// Catch up on the stories that arrived while analysis was paused
+	if !analysisPaused.Load() && !backendDown.Load() {
+		stories = append(takePaused(), stories...)
+	}
+	if unfinished := analyzeAll(ctx, cfg, stories, h); len(unfinished) > 0 {
+		slog.Warn("cycle cut short", "err", ctx.Err(), "unfinished", len(unfinished), "policy", cfg.CycleTimeoutPolicy)
+		if cfg.CycleTimeoutPolicy == "carry" {
+			// Forgetting them lets the next cycle fetch them again
+			for _, story := range unfinished {
+				seen.forget(story.ID)
+			}
+		}
 	}
 
-	return entries
+	return err
 }
 
-// Formats entries with a fading effect by applying different colors based on age
-func formatEntriesWithFade(entries []string) string {
-	var formattedEntries []string
+// Analyzes stories with up to -analyze-workers running at once. Results are
+// reported one at a time and in the order of stories, however the analyses
+// finish, so handlers needn't be safe for concurrent use and the feed keeps
+// its recency order. Once ctx is done no more analyses start; the stories
+// left unanalyzed are returned.
+func analyzeAll(ctx context.Context, cfg Config, stories []Story, h feedHandlers) []Story {
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		workers    = make(chan struct{}, cfg.AnalyzeWorkers)
+		unfinished []Story
 
-	for i, entry := range entries {
-		// Determine the fade level based on the entry's position in the list
-		fadeIndex := i * (len(fadeLevels) - 1) / len(entries)
-		color := fadeLevels[fadeIndex]
-		formattedEntries = append(formattedEntries, color+entry+"[-]")
+		// Finished results held back until every earlier one is reported
+		reports = make([]func(), len(stories))
+		done    = make([]bool, len(stories))
+		next    int
+	)
+
+	// Too many stories to analyze on a modest machine: show headlines only
+	// until a cycle brings a manageable number again
+	pending := 0
+	for _, story := range stories {
+		if titleFilter(cfg, story) == "" {
+			pending++
+		}
 	}
+	headlineOnly := cfg.MaxPending > 0 && pending > cfg.MaxPending
+	if headlineOnly {
+		slog.Warn("too many stories to analyze, showing headlines only", "pending", pending, "max", cfg.MaxPending)
+		stats.headlineOnly.Store(int64(pending))
+	} else {
+		stats.headlineOnly.Store(0)
+	}
+
+	// Records the outcome of stories[i], then reports every result that's
+	// no longer waiting on an earlier one. Called with mu held.
+	complete := func(i int, report func()) {
+		reports[i], done[i] = report, true
+		for ; next < len(stories) && done[next]; next++ {
+			if reports[next] != nil {
+				reports[next]()
+				reports[next] = nil
+			}
+		}
+	}
+
+	for i, story := range stories {
+		// Save model time for titles that don't look relevant
+		if reason := titleFilter(cfg, story); reason != "" {
+			stats.preFiltered.Add(1)
+			mu.Lock()
+			complete(i, func() { h.skip(cfg, story, reason) })
+			mu.Unlock()
+			continue
+		}
+		if paused, down := analysisPaused.Load(), backendDown.Load(); headlineOnly || paused || down {
+			note := "Not analyzed: too many stories queued (-max-pending)"
+			switch {
+			case down && cfg.OnBackendDown == "retry":
+				note = "Not analyzed yet: waiting for Ollama to come up"
+				queuePaused(story)
+			case down:
+				note = "Not analyzed: Ollama was unavailable at startup"
+			case paused:
+				note = "Not analyzed yet: analysis is paused"
+				queuePaused(story)
+			}
+			insight := headlineInsight(story, note)
+			mu.Lock()
+			complete(i, func() { h.insight(cfg, insight, nil) })
+			mu.Unlock()
+			continue
+		}
+
+		select {
+		case workers <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			mu.Lock()
+			unfinished = append(unfinished, stories[i:]...)
+			for j := i; j < len(stories); j++ {
+				complete(j, nil)
+			}
+			mu.Unlock()
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
 
-	return strings.Join(formattedEntries, "\n\n")
+			// Use Ollama to determine if this story is high-value. A panic
+			// fails just this story; left alone it would take down the
+			// whole program from this goroutine.
+			insight, err := func() (insight HighValueInsight, err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						slog.Error("analysis panicked", "id", story.ID, "panic", r, "stack", string(debug.Stack()))
+						insight, err = headlineInsight(story, ""), fmt.Errorf("internal error: %v", r)
+					}
+				}()
+				return analyzeStory(ctx, cfg, story)
+			}()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && ctx.Err() != nil {
+				unfinished = append(unfinished, story)
+				complete(i, nil)
+				return
+			}
+			if reason := lowConfidence(cfg, insight); err == nil && reason != "" {
+				complete(i, func() { h.skip(cfg, story, reason) })
+				return
+			}
+			complete(i, func() { h.insight(cfg, insight, err) })
+		}()
+	}
+	wg.Wait()
+	return unfinished
 }
 
-// Fetches the top stories from Hacker News API, filtering out already-seen stories
-func fetchTopStories(seenStoryIDs map[int]bool) ([]Story, error) {
-	resp, err := http.Get("https://hacker-news.firebaseio.com/v0/topstories.json")
-	if err != nil {
-		return nil, err
+// Returns a placeholder insight for a story shown without analysis, with
+// note in place of the summary
+func headlineInsight(story Story, note string) HighValueInsight {
+	return HighValueInsight{
+		ID:            story.ID,
+		Title:         story.Title,
+		URL:           story.URL,
+		DiscussionURL: story.DiscussionURL,
+		Summary:       note,
+		Priority:      "N/A",
+		Unanalyzed:    true,
+	}
+}
+
+// Counts, logs and, unless -quiet suppresses it, reports a failed fetch
+func reportFetchError(cfg Config, err error, h feedHandlers) {
+	stats.errors.Add(1)
+	slog.Error("fetch failed", "err", err)
+	if !suppressError(cfg, err) {
+		h.Error(err)
+	}
+}
+
+// Analyzes a story and applies the configured adjustments to the result
+func analyzeStory(ctx context.Context, cfg Config, story Story) (HighValueInsight, error) {
+	start := time.Now()
+	insight, err := analyzeWithOllama(ctx, cfg, story)
+	insight.AnalysisDuration = time.Since(start)
+	insight.DiscussionURL = story.DiscussionURL
+	if err == nil {
+		stats.latency.add(insight.AnalysisDuration)
+		stats.throughput.add(time.Now())
+		insight = boostPriority(insight, story, cfg)
+		insight = applyRules(insight, cfg.Rules)
+	}
+	if !cfg.KeepRaw {
+		insight.Raw = ""
+	}
+	return insight, err
+}
+
+// Returns why a story falls outside the -max-age or -since window, or "" if
+// it's recent enough
+func tooOld(cfg Config, story Story) string {
+	if cfg.MaxAge > 0 && story.Age() > cfg.MaxAge {
+		return "older than -max-age"
+	}
+	if !cfg.Since.IsZero() && !time.Unix(story.Time, 0).After(cfg.Since) {
+		return "before -since"
+	}
+	return ""
+}
+
+// Returns why a story's title fails the pre-filters and isn't worth
+// analyzing, or "" if it passes them all. -exclude-regex is checked first,
+// then -include-regex, then the -keyword-weights score; a title has to pass
+// every filter that's set.
+func titleFilter(cfg Config, story Story) string {
+	if cfg.ExcludeRegex != nil && cfg.ExcludeRegex.MatchString(story.Title) {
+		return "matches -exclude-regex"
+	}
+	if cfg.IncludeRegex != nil && !cfg.IncludeRegex.MatchString(story.Title) {
+		return "doesn't match -include-regex"
+	}
+	if cfg.KeywordWeights == nil {
+		return ""
+	}
+	if score := scoreTitle(story.Title, cfg.KeywordWeights); score < cfg.MinKeywordScore {
+		return fmt.Sprintf("keyword score %d below -min-keyword-score", score)
+	}
+	return ""
+}
+
+// Randomly lengthens or shortens d by up to the given fraction so a fleet of
+// instances doesn't poll at the same instant
+func jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+	offset := (rand.Float64()*2 - 1) * fraction
+	return time.Duration(float64(d) * (1 + offset))
+}
+
+// Reports whether -quiet keeps an error out of the feed. Suppressed errors
+// are still logged and counted.
+func suppressError(cfg Config, err error) bool {
+	if !cfg.Quiet {
+		return false
+	}
+	return cfg.QuietLevel == "all" || isTransient(err)
+}
+
+// Reorders a batch of stories in place according to the analyze-order policy:
+// "fifo" keeps fetch order, "lifo" reverses it and "score-desc" puts the
+// highest-scoring stories first
+func orderStories(stories []Story, policy string) {
+	switch policy {
+	case "lifo":
+		slices.Reverse(stories)
+	case "score-desc":
+		sort.SliceStable(stories, func(i, j int) bool {
+			return stories[i].Score > stories[j].Score
+		})
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
+// Fetches up to limit top stories from Hacker News API, filtering out
+// already-seen stories
+func fetchTopStories(ctx context.Context, cfg Config, seen *seenSet, limit int, skip func(Story, string)) ([]Story, error) {
+	body, _, err := fetchJSON(ctx, hnAPIBase+"/topstories.json")
 	if err != nil {
 		return nil, err
 	}
 
-	var storyIDs []int
-	if err := json.Unmarshal(body, &storyIDs); err != nil {
+	storyIDs, err := decodeStoryIDs(body)
+	if err != nil {
 		return nil, err
 	}
 
-	// Fetch details for the first numStoriesFetch unique stories that haven't been seen
+	// Fetch details for the first limit unique stories that haven't been
+	// seen, up to -fetch-workers at a time
 	var stories []Story
-	for _, id := range storyIDs {
-		if !seenStoryIDs[id] { // Check if story has already been displayed
-			story, err := fetchStoryDetails(id)
+	for next := 0; next < len(storyIDs) && len(stories) < limit; {
+		var batch []int
+		for ; next < len(storyIDs) && len(batch) < min(cfg.FetchWorkers, limit-len(stories)); next++ {
+			stats.scanned.Add(1)
+			if id := storyIDs[next]; !seen.hasID(hnID(id)) { // Check if story has already been displayed
+				batch = append(batch, id)
+			}
+		}
+
+		details, errs := fetchStoryBatch(ctx, batch)
+		for i, id := range batch {
+			story, err := details[i], errs[i]
+			if errors.Is(err, errMalformedStory) {
+				// Retrying won't fix the item, so don't fetch it again
+				slog.Warn("skipping story", "id", id, "err", err)
+				seen.add(hnID(id), "")
+				continue
+			}
 			if err == nil {
+				// Skip reposts of an article that was already shown
+				duplicate := seen.hasURL(story.URL)
+				seen.add(hnID(id), story.URL) // Mark as seen
+				if duplicate {
+					skip(story, "repost of a seen URL")
+					continue
+				}
+
+				// Skip stale stories; they'd only get older on later cycles
+				if reason := tooOld(cfg, story); reason != "" {
+					skip(story, reason)
+					continue
+				}
 				stories = append(stories, story)
-				seenStoryIDs[id] = true // Mark as seen
 			}
 		}
-		if len(stories) >= numStoriesFetch {
-			break
-		}
 	}
 
 	return stories, nil
 }
 
+// Fetches the details of several stories concurrently, returning the stories
+// and errors in the order of ids
+func fetchStoryBatch(ctx context.Context, ids []int) ([]Story, []error) {
+	stories := make([]Story, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stories[i], errs[i] = fetchStoryDetails(ctx, id)
+		}()
+	}
+	wg.Wait()
+	return stories, errs
+}
+
+// Reported for items that can't be displayed, such as ones without a title
+var errMalformedStory = errors.New("malformed story")
+
+// JSON keys decodeStory reads the title and URL from; set from -title-field
+// and -url-field for HN-compatible APIs that name them differently
+var storyFields = struct{ title, url string }{"title", "url"}
+
+// Decodes an HN item, tolerating title and url fields that are null or not
+// plain strings. An item that ends up without a title is rejected.
+func decodeStory(body []byte) (Story, error) {
+	var item struct {
+		Story
+		Title json.RawMessage `json:"title"`
+		URL   json.RawMessage `json:"url"`
+	}
+	if err := json.Unmarshal(body, &item); err != nil {
+		return Story{}, &parseError{Err: err}
+	}
+
+	title, url := item.Title, item.URL
+	if storyFields.title != "title" || storyFields.url != "url" {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return Story{}, &parseError{Err: err}
+		}
+		title, url = fields[storyFields.title], fields[storyFields.url]
+	}
+
+	story := item.Story
+	story.Title = strings.TrimSpace(looseString(title))
+	story.URL = looseString(url)
+	if story.Title == "" {
+		return Story{}, &parseError{ID: story.ID, Err: fmt.Errorf("item %s has no title", story.ID)}
+	}
+	return story, nil
+}
+
+// Decodes a list of item IDs. Entries that are strings or whole floats are
+// converted; anything else is skipped with a warning rather than failing the
+// whole list.
+func decodeStoryIDs(body []byte) ([]int, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(raw))
+	for _, entry := range raw {
+		var n json.Number
+		if json.Unmarshal(entry, &n) != nil {
+			// Quoted IDs such as "8863"
+			var s string
+			if json.Unmarshal(entry, &s) != nil {
+				slog.Warn("skipping story ID", "id", string(entry))
+				continue
+			}
+			n = json.Number(strings.TrimSpace(s))
+		}
+		id, err := n.Int64()
+		if err != nil {
+			f, ferr := n.Float64()
+			if ferr != nil || f != math.Trunc(f) {
+				slog.Warn("skipping story ID", "id", string(entry))
+				continue
+			}
+			id = int64(f)
+		}
+		if id <= 0 {
+			slog.Warn("skipping story ID", "id", string(entry))
+			continue
+		}
+		ids = append(ids, int(id))
+	}
+	return ids, nil
+}
+
+// Returns a JSON string value, or the first string of an array; anything
+// else, including null, yields ""
+func looseString(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(raw, &list) == nil {
+		for _, elem := range list {
+			if json.Unmarshal(elem, &s) == nil {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// Returns how long ago the story was submitted
+func (s Story) Age() time.Duration {
+	return time.Since(time.Unix(s.Time, 0))
+}
+
 // Fetches story details for a given story ID
-func fetchStoryDetails(id int) (Story, error) {
-	url := fmt.Sprintf("https://hacker-news.firebaseio.com/v0/item/%d.json", id)
-	resp, err := http.Get(url)
-	if err != nil {
-		return Story{}, err
+func fetchStoryDetails(ctx context.Context, id int) (Story, error) {
+	if story, ok := storyCache.get(id); ok {
+		stats.cacheHits.Add(1)
+		return story, nil
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, header, err := fetchJSON(ctx, fmt.Sprintf("%s/item/%d.json", hnAPIBase, id))
 	if err != nil {
 		return Story{}, err
 	}
 
-	var story Story
-	if err := json.Unmarshal(body, &story); err != nil {
+	story, err := decodeStory(body)
+	if err != nil {
 		return Story{}, err
 	}
+	// Text posts such as Ask HN have no URL of their own
+	story.DiscussionURL = fmt.Sprintf("https://news.ycombinator.com/item?id=%d", id)
+	if story.URL == "" {
+		story.URL = story.DiscussionURL
+	}
+	storyCache.put(id, story, header)
 
 	return story, nil
 }
 
+// Reads a response body that should be JSON, failing with a clear error when
+// the server returned something else, such as an HTML error page
+func readJSONBody(resp *http.Response) ([]byte, error) {
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		if contentType == "" {
+			contentType = "no Content-Type"
+		}
+		return nil, &responseError{
+			StatusCode: resp.StatusCode,
+			msg:        fmt.Sprintf("expected JSON, got %s (status %d)", contentType, resp.StatusCode),
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &responseError{
+			StatusCode: resp.StatusCode,
+			msg:        fmt.Sprintf("unexpected status %d from %s", resp.StatusCode, resp.Request.URL),
+		}
+	}
+
+	// Read one byte past the cap to tell a body of exactly the cap from a
+	// longer one
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBodyBytes {
+		return nil, fmt.Errorf("response too large: %s sent more than %d bytes (-max-body-bytes)", resp.Request.URL, maxBodyBytes)
+	}
+	return body, nil
+}
+
+// Largest response body readJSONBody accepts; set from -max-body-bytes
+var maxBodyBytes int64 = 4 << 20
+
 // Uses Ollama to analyze and classify the importance of an article
-func analyzeWithOllama(story Story) (HighValueInsight, error) {
+func analyzeWithOllama(ctx context.Context, cfg Config, story Story) (HighValueInsight, error) {
 	// Format the prompt for Ollama to analyze the story
-	prompt := fmt.Sprintf("You are an expert cybersecurity analyst. Analyze the following headline and URL to determine its relevance and priority in cybersecurity. Respond with a priority level (e.g., High, Medium, Low) and provide a summary if relevant. Keep everything very short.\n\nTitle: %s\nURL: %s", story.Title, story.URL)
+	f := focuses[cfg.Focus]
+	prompt := fmt.Sprintf("You are an expert %s. Analyze the following headline and URL to determine its relevance and priority in %s. Respond with only a JSON object, without markdown, of the form {\"priority\": \"High, Medium or Low\", \"summary\": \"a short summary if relevant\", \"rationale\": \"one sentence explaining the priority\", \"confidence\": your confidence in the priority from 0 to 1}. Keep everything very short.%s\n\nTitle: %s\nURL: %s", f.Analyst, f.Field, languageInstruction(cfg.Lang), story.Title, story.URL)
 
-	// Run Ollama command with `ollama run`
-	cmd := exec.Command("ollama", "run", "llama3.2", prompt)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err := cmd.Run()
+	output, err := runOllama(ctx, prompt)
 	if err != nil {
-		return HighValueInsight{}, fmt.Errorf("failed to execute Ollama command: %v", err)
+		return HighValueInsight{ID: story.ID, Title: story.Title, URL: story.URL}, &analysisError{ID: story.ID, Err: err}
 	}
 
-	// Parse the output from Ollama
-	output := out.String()
-	lines := strings.Split(output, "\n")
-	if len(lines) < 2 {
+	if reply, ok := parseModelReply(output); ok {
 		return HighValueInsight{
-			Title:    story.Title,
-			URL:      story.URL,
-			Summary:  "[red]Invalid response format from Ollama[-]",
-			Priority: "Low",
+			ID:         story.ID,
+			Title:      story.Title,
+			URL:        story.URL,
+			Summary:    reply.Summary,
+			Priority:   reply.Priority,
+			Rationale:  reply.Rationale,
+			Confidence: reply.confidence(),
+			Raw:        output,
 		}, nil
 	}
 
-	priority := lines[0]
-	summary := strings.Join(lines[1:], " ")
+	// Without usable JSON, take a labeled or standalone level from the text
+	// and keep all of it as the summary rather than guess at its layout
+	rationale, lines := extractLabeledLine(strings.Split(output, "\n"), "Rationale")
+	confidence, lines := extractLabeledLine(lines, "Confidence")
+	text := strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+	priority := priorityLevel(text)
+	if priority == "" {
+		return HighValueInsight{
+			ID:         story.ID,
+			Title:      story.Title,
+			URL:        story.URL,
+			Summary:    "[red]Invalid response format from Ollama[-]",
+			Priority:   priorityLow,
+			Confidence: neutralConfidence,
+			Raw:        output,
+		}, nil
+	}
 
 	return HighValueInsight{
-		Title:    story.Title,
-		URL:      story.URL,
-		Summary:  summary,
-		Priority: priority,
+		ID:         story.ID,
+		Title:      story.Title,
+		URL:        story.URL,
+		Summary:    text,
+		Priority:   priority,
+		Rationale:  rationale,
+		Confidence: parseConfidence(confidence),
+		Raw:        output,
 	}, nil
 }
+
+// Runs the analysis model on a prompt on one of the -ollama-url servers and
+// returns its output, which is never empty
+func runOllama(ctx context.Context, prompt string) (string, error) {
+	queryCtx := ctx
+	if ollamaTimeout > 0 {
+		var cancel context.CancelFunc
+		queryCtx, cancel = context.WithTimeout(ctx, ollamaTimeout)
+		defer cancel()
+	}
+
+	ep := acquireEndpoint()
+	output, err := queryOllama(queryCtx, ep.url, analysisModel, prompt)
+	// An interrupted request says nothing about the endpoint's health, but
+	// one that timed out does
+	ep.release(err == nil || ctx.Err() != nil)
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("Ollama at %s didn't answer within %v (-ollama-timeout)", ep.url, ollamaTimeout)
+		}
+		return "", err
+	}
+
+	// Stray invalid bytes would garble the TUI's rendering
+	output = strings.ToValidUTF8(output, "\uFFFD")
+	if strings.TrimSpace(output) == "" {
+		return "", errEmptyResponse
+	}
+	return output, nil
+}
+
+// Longest the -warmup prompt may take before the feed starts without it
+const warmupTimeout = time.Minute
+
+// Loads the model into memory with a trivial prompt so the first real
+// analysis isn't slowed by it. Failures are only logged.
+func warmUpModel(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := runOllama(ctx, "Reply with OK."); err != nil {
+		slog.Warn("model warm-up failed", "err", err)
+		return
+	}
+	slog.Info("model warmed up", "took", time.Since(start).Round(time.Millisecond))
+}
+
+// Returns the prompt sentence asking for a reply in lang, or "" for English.
+// The priority stays in English so priorityLevel can still recognize it.
+func languageInstruction(lang string) string {
+	if lang == "" || strings.EqualFold(lang, "english") || strings.EqualFold(lang, "en") {
+		return ""
+	}
+	return fmt.Sprintf(" Respond in %s, but write the priority level and any labels or JSON keys in English.", lang)
+}
+
+// Returned when the model printed nothing, e.g. because it refused or timed
+// out inside Ollama; unlike malformed output, trying again may help
+var errEmptyResponse = errors.New("empty model response")
+
+// Reports whether an Ollama error message says the requested model hasn't
+// been pulled
+func isModelNotFound(errMsg string) bool {
+	msg := strings.ToLower(errMsg)
+	return strings.Contains(msg, "model") &&
+		(strings.Contains(msg, "not found") || strings.Contains(msg, "file does not exist"))
+}
+
+// Finds the first line of the form "Label: value", tolerating markdown
+// emphasis around the label, and returns its value along with the remaining
+// lines. The value is empty when no line has the label.
+func extractLabeledLine(lines []string, label string) (string, []string) {
+	prefix := strings.ToLower(label) + ":"
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(strings.TrimSpace(line), "*_ ")
+		if !strings.HasPrefix(strings.ToLower(trimmed), prefix) {
+			continue
+		}
+		value := strings.Trim(trimmed[len(prefix):], "*_ ")
+		return value, append(lines[:i:i], lines[i+1:]...)
+	}
+	return "", lines
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// A model reply in the JSON the analysis prompt asks for
+const jsonReply = `{"priority": "High", "summary": "Summary", "rationale": "Reason", "confidence": 0.9}`
+
+// Stories titled "Story 0" to "Story n-1"
+func testStories(n int) []Story {
+	stories := make([]Story, n)
+	for i := range stories {
+		stories[i] = Story{ID: hnID(i + 1), Title: fmt.Sprintf("Story %d", i), URL: fmt.Sprintf("https://example.com/%d", i)}
+	}
+	return stories
+}
+
+// Handlers that collect the insights reported, in order
+func collectingHandlers(insights *[]HighValueInsight) feedHandlers {
+	return feedHandlers{
+		Insight: func(insight HighValueInsight, err error) {
+			*insights = append(*insights, insight)
+		},
+		Error:   func(error) {},
+		Skipped: func(Story, string) {},
+	}
+}
+
+func TestAnalyzeWithOllamaModelNotFound(t *testing.T) {
+	fakeOllama(t, func(string) (int, string) {
+		return http.StatusNotFound, `model "llama3.2" not found, try pulling it first`
+	})
+
+	story := Story{ID: hnID(1), Title: "Title", URL: "https://example.com/"}
+	_, err := analyzeWithOllama(context.Background(), Config{Focus: "cybersecurity"}, story)
+	if err == nil {
+		t.Fatal("analyzeWithOllama() error = nil, want a model-not-found error")
+	}
+	if !strings.Contains(err.Error(), "ollama pull llama3.2") {
+		t.Errorf("analyzeWithOllama() error = %q, want it to suggest `ollama pull llama3.2`", err)
+	}
+}
+
+func TestAnalyzeAllCapsConcurrency(t *testing.T) {
+	for _, workers := range []int{1, 2, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			var inFlight, peak atomic.Int32
+			fakeOllama(t, func(string) (int, string) {
+				n := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				return http.StatusOK, jsonReply
+			})
+
+			var insights []HighValueInsight
+			cfg := Config{Focus: "cybersecurity", AnalyzeWorkers: workers}
+			analyzeAll(context.Background(), cfg, testStories(8), collectingHandlers(&insights))
+
+			if len(insights) != 8 {
+				t.Fatalf("analyzeAll() reported %d insights, want 8", len(insights))
+			}
+			if got := peak.Load(); got > int32(workers) {
+				t.Errorf("peak concurrent analyses = %d, want at most -analyze-workers %d", got, workers)
+			}
+		})
+	}
+}
+
+func TestAnalyzeAllReportsInFetchOrder(t *testing.T) {
+	// Later stories finish first, so completions arrive in reverse
+	const n = 6
+	title := regexp.MustCompile(`Title: Story (\d+)`)
+	fakeOllama(t, func(prompt string) (int, string) {
+		i, _ := strconv.Atoi(title.FindStringSubmatch(prompt)[1])
+		time.Sleep(time.Duration(n-i) * 15 * time.Millisecond)
+		return http.StatusOK, jsonReply
+	})
+
+	var insights []HighValueInsight
+	cfg := Config{Focus: "cybersecurity", AnalyzeWorkers: n}
+	analyzeAll(context.Background(), cfg, testStories(n), collectingHandlers(&insights))
+
+	if len(insights) != n {
+		t.Fatalf("analyzeAll() reported %d insights, want %d", len(insights), n)
+	}
+	for i, insight := range insights {
+		if want := fmt.Sprintf("Story %d", i); insight.Title != want {
+			t.Errorf("insight %d = %q, want %q", i, insight.Title, want)
+		}
+	}
+}
+
+// Panics on its first fetch and returns one story after that
+type panickingSource struct{ fetches atomic.Int32 }
+
+func (*panickingSource) Name() string { return "Test" }
+func (*panickingSource) Due() bool    { return true }
+func (s *panickingSource) Fetch(context.Context, Config, *seenSet, int, func(Story, string)) ([]Story, error) {
+	if s.fetches.Add(1) == 1 {
+		panic("source bug")
+	}
+	return testStories(1), nil
+}
+
+func TestPollFeedSurvivesPanickingSource(t *testing.T) {
+	fakeOllama(t, func(string) (int, string) { return http.StatusOK, jsonReply })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var (
+		errs     []error
+		insights []HighValueInsight
+	)
+	h := feedHandlers{
+		Insight: func(insight HighValueInsight, err error) {
+			insights = append(insights, insight)
+			cancel()
+		},
+		Error:   func(err error) { errs = append(errs, err) },
+		Skipped: func(Story, string) {},
+	}
+	source := &panickingSource{}
+	cfg := Config{
+		Focus:          "cybersecurity",
+		AnalyzeWorkers: 1,
+		FetchCount:     1,
+		Interval:       10 * time.Millisecond,
+		Sources:        []Source{source},
+	}
+	pollFeed(ctx, cfg, newSeenSet(10), nil, h)
+
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "internal error: source bug") {
+		t.Errorf("pollFeed() errors = %v, want one internal error for the panic", errs)
+	}
+	if len(insights) != 1 || insights[0].Title != "Story 0" {
+		t.Fatalf("pollFeed() insights = %+v, want Story 0 from the cycle after the panic", insights)
+	}
+	if got := source.fetches.Load(); got != 2 {
+		t.Errorf("source fetched %d times, want 2", got)
+	}
+}
+
+func TestAnalyzeAllContainsAnalysisPanic(t *testing.T) {
+	// With no endpoints, picking one panics; the first story's report then
+	// brings up a server for the rest. One worker runs the stories in turn.
+	saved := ollamaPool.endpoints
+	t.Cleanup(func() { ollamaPool.endpoints = saved })
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"response": jsonReply, "done": true})
+	}))
+	t.Cleanup(srv.Close)
+	setOllamaEndpoints(nil)
+
+	var (
+		insights []HighValueInsight
+		errs     []error
+	)
+	h := feedHandlers{
+		Insight: func(insight HighValueInsight, err error) {
+			insights = append(insights, insight)
+			errs = append(errs, err)
+			setOllamaEndpoints([]string{srv.URL})
+		},
+		Error:   func(error) {},
+		Skipped: func(Story, string) {},
+	}
+	cfg := Config{Focus: "cybersecurity", AnalyzeWorkers: 1}
+	unfinished := analyzeAll(context.Background(), cfg, testStories(3), h)
+
+	if len(unfinished) != 0 || len(insights) != 3 {
+		t.Fatalf("analyzeAll() reported %d insights and %d unfinished, want 3 and 0", len(insights), len(unfinished))
+	}
+	if errs[0] == nil || !strings.Contains(errs[0].Error(), "internal error") {
+		t.Errorf("story 0 error = %v, want an internal error", errs[0])
+	}
+	if insights[0].Title != "Story 0" {
+		t.Errorf("story 0 title = %q, want the headline kept", insights[0].Title)
+	}
+	for i := 1; i < 3; i++ {
+		if errs[i] != nil || insights[i].Priority != "High" {
+			t.Errorf("story %d = %q, %v; want an analyzed High story", i, insights[i].Priority, errs[i])
+		}
+	}
+}
+
+func TestAnalyzeWithOllamaEmptyResponse(t *testing.T) {
+	for _, output := range []string{"", "   ", "\n\t\n"} {
+		t.Run(strconv.Quote(output), func(t *testing.T) {
+			fakeOllama(t, func(string) (int, string) { return http.StatusOK, output })
+
+			_, err := analyzeWithOllama(context.Background(), Config{Focus: "cybersecurity"}, testStories(1)[0])
+			if !errors.Is(err, errEmptyResponse) {
+				t.Errorf("analyzeWithOllama() error = %v, want %v", err, errEmptyResponse)
+			}
+		})
+	}
+}
+
+func TestAnalyzeWithOllamaCancelled(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fakeOllama(t, func(string) (int, string) {
+		close(started)
+		<-release // A model that would take far longer than the test
+		return http.StatusOK, jsonReply
+	})
+	t.Cleanup(func() { close(release) }) // Runs before the server is closed
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	begin := time.Now()
+	_, err := analyzeWithOllama(ctx, Config{Focus: "cybersecurity"}, testStories(1)[0])
+	// Callers tell a cancelled analysis from a failed one by ctx.Err()
+	if err == nil {
+		t.Fatal("analyzeWithOllama() error = nil, want the cancellation")
+	}
+	if took := time.Since(begin); took > 2*time.Second {
+		t.Errorf("analyzeWithOllama() took %v to return after cancellation", took)
+	}
+}
+
+// Starts a fake HN API serving the given top story IDs, each an item titled
+// "Story N", and fetches from it for the rest of the test. Returns a function
+// that changes the top stories.
+func fakeHN(t *testing.T, top ...int) func(...int) {
+	t.Helper()
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v0/topstories.json" {
+			mu.Lock()
+			defer mu.Unlock()
+			json.NewEncoder(w).Encode(top)
+			return
+		}
+		item, ok := strings.CutPrefix(r.URL.Path, "/v0/item/")
+		id, err := strconv.Atoi(strings.TrimSuffix(item, ".json"))
+		if !ok || err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"id": %d, "type": "story", "title": "Story %d", "url": "https://example.com/%d"}`, id, id, id)
+	}))
+	t.Cleanup(srv.Close)
+
+	saved := hnAPIBase
+	t.Cleanup(func() { hnAPIBase = saved })
+	hnAPIBase = srv.URL + "/v0"
+	return func(ids ...int) {
+		mu.Lock()
+		defer mu.Unlock()
+		top = ids
+	}
+}
+
+func TestFetchTopStoriesSkipsSeen(t *testing.T) {
+	setTop := fakeHN(t, 1, 2, 3)
+	cfg := Config{FetchWorkers: 4}
+	seen := newSeenSet(maxSeenStories)
+	skip := func(Story, string) {}
+	titles := func(stories []Story) []string {
+		var titles []string
+		for _, story := range stories {
+			titles = append(titles, story.Title)
+		}
+		return titles
+	}
+
+	first, err := fetchTopStories(context.Background(), cfg, seen, 10, skip)
+	if err != nil {
+		t.Fatalf("first fetchTopStories() error = %v", err)
+	}
+	if got, want := titles(first), []string{"Story 1", "Story 2", "Story 3"}; !slices.Equal(got, want) {
+		t.Fatalf("first fetchTopStories() = %v, want %v", got, want)
+	}
+
+	// The same list again brings nothing new
+	again, err := fetchTopStories(context.Background(), cfg, seen, 10, skip)
+	if err != nil {
+		t.Fatalf("second fetchTopStories() error = %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("second fetchTopStories() = %v, want no stories", titles(again))
+	}
+
+	// New stories among seen ones are the only ones returned
+	setTop(4, 1, 2, 5, 3)
+	mixed, err := fetchTopStories(context.Background(), cfg, seen, 10, skip)
+	if err != nil {
+		t.Fatalf("third fetchTopStories() error = %v", err)
+	}
+	if got, want := titles(mixed), []string{"Story 4", "Story 5"}; !slices.Equal(got, want) {
+		t.Errorf("third fetchTopStories() = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeStoryIDs(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/topstories_mixed.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []int
+		wantErr bool
+	}{
+		{"integers", `[1, 2, 3]`, []int{1, 2, 3}, false},
+		{"empty", `[]`, []int{}, false},
+		{"mixed and garbage entries", string(fixture), []int{8863, 8864, 8865, 8866, 1000, 1000, 8870}, false},
+		{"not an array", `{"ids": [1]}`, nil, true},
+		{"not JSON", `<html>`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeStoryIDs([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeStoryIDs() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("decodeStoryIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidUTF8IsReplaced(t *testing.T) {
+	t.Run("model output", func(t *testing.T) {
+		// Written by hand, since encoding/json would clean the bytes up itself
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("{\"response\": \"Priority: High\\nBroken \xff\xfe bytes \xc3\"}"))
+		}))
+		defer srv.Close()
+		useOllama(t, srv.URL)
+
+		insight, err := analyzeWithOllama(context.Background(), Config{Focus: "cybersecurity"}, testStories(1)[0])
+		if err != nil {
+			t.Fatalf("analyzeWithOllama() error = %v", err)
+		}
+		if !utf8.ValidString(insight.Summary) || !strings.Contains(insight.Summary, "Broken \uFFFD") {
+			t.Errorf("analyzeWithOllama() summary = %q, want invalid bytes replaced with U+FFFD", insight.Summary)
+		}
+		if insight.Priority != priorityHigh {
+			t.Errorf("analyzeWithOllama() priority = %q, want %q", insight.Priority, priorityHigh)
+		}
+	})
+
+	t.Run("fetched title", func(t *testing.T) {
+		story, err := decodeStory([]byte("{\"id\": 1, \"title\": \"Caf\xe9 \xff breach\", \"url\": \"https://example.com/\"}"))
+		if err != nil {
+			t.Fatalf("decodeStory() error = %v", err)
+		}
+		if !utf8.ValidString(story.Title) {
+			t.Errorf("decodeStory() title = %q, want valid UTF-8", story.Title)
+		}
+	})
+}
+
+func TestAnalyzeWithOllamaParsing(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		wantPriority string
+		wantSummary  string
+	}{
+		{"JSON object", jsonReply, priorityHigh, "Summary"},
+		{"JSON in a code fence", "```json\n" + jsonReply + "\n```", priorityHigh, "Summary"},
+		{"JSON with lowercase priority", `{"priority": "medium", "summary": "Patch released"}`, priorityMedium, "Patch released"},
+		{"labeled lines", "Priority: **Low**\nA minor release.\nRationale: Routine.", priorityLow, "Priority: **Low** A minor release."},
+		{"level in prose", "This is high risk for banks.", priorityHigh, "This is high risk for banks."},
+		{"no level", "I can't assess this headline.", priorityLow, "[red]Invalid response format from Ollama[-]"},
+		{"JSON without a known level", `{"priority": "urgent", "summary": "x"}`, priorityLow, "[red]Invalid response format from Ollama[-]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeOllama(t, func(string) (int, string) { return http.StatusOK, tt.output })
+
+			insight, err := analyzeWithOllama(context.Background(), Config{Focus: "cybersecurity"}, testStories(1)[0])
+			if err != nil {
+				t.Fatalf("analyzeWithOllama() error = %v", err)
+			}
+			if insight.Priority != tt.wantPriority || insight.Summary != tt.wantSummary {
+				t.Errorf("analyzeWithOllama() = %q, %q; want %q, %q", insight.Priority, insight.Summary, tt.wantPriority, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestAnalyzeWithOllamaServerError(t *testing.T) {
+	fakeOllama(t, func(string) (int, string) { return http.StatusInternalServerError, "out of memory" })
+
+	story := testStories(1)[0]
+	insight, err := analyzeWithOllama(context.Background(), Config{Focus: "cybersecurity"}, story)
+	var aerr *analysisError
+	if !errors.As(err, &aerr) || aerr.ID != story.ID {
+		t.Fatalf("analyzeWithOllama() error = %v, want an analysisError for story %s", err, story.ID)
+	}
+	if !strings.Contains(err.Error(), "out of memory") {
+		t.Errorf("analyzeWithOllama() error = %q, want Ollama's message", err)
+	}
+	if insight.Title != story.Title {
+		t.Errorf("analyzeWithOllama() title = %q, want the story's %q", insight.Title, story.Title)
+	}
+}
+
+func TestDiscussionURLOnlyForHNItems(t *testing.T) {
+	fakeHN(t)
+	fetched, err := fetchStoryDetails(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("fetchStoryDetails() error = %v", err)
+	}
+	if want := "https://news.ycombinator.com/item?id=42"; fetched.DiscussionURL != want {
+		t.Errorf("fetchStoryDetails() discussion URL = %q, want %q", fetched.DiscussionURL, want)
+	}
+
+	// -stdin lines go through decodeStory alone
+	piped, err := decodeStory([]byte(`{"title": "Piped story"}`))
+	if err != nil {
+		t.Fatalf("decodeStory() error = %v", err)
+	}
+	if piped.DiscussionURL != "" || piped.URL != "" {
+		t.Errorf("decodeStory() = URL %q, discussion %q; want both empty", piped.URL, piped.DiscussionURL)
+	}
+}
+
+func TestAnalyzeWithOllamaLanguage(t *testing.T) {
+	tests := []struct {
+		lang      string
+		reply     string
+		wantAsked bool
+		want      HighValueInsight
+	}{
+		{"", jsonReply, false, HighValueInsight{Priority: "High", Summary: "Summary"}},
+		{"en", jsonReply, false, HighValueInsight{Priority: "High", Summary: "Summary"}},
+		{
+			"German",
+			`{"priority": "High", "summary": "Kritische Lücke in OpenSSL", "rationale": "Weit verbreitet", "confidence": 0.8}`,
+			true,
+			HighValueInsight{Priority: "High", Summary: "Kritische Lücke in OpenSSL"},
+		},
+		{
+			"Spanish",
+			"Priority: Medium\nFallo en el kernel de Linux",
+			true,
+			HighValueInsight{Priority: "Medium", Summary: "Priority: Medium Fallo en el kernel de Linux"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			var prompt string
+			fakeOllama(t, func(p string) (int, string) {
+				prompt = p
+				return http.StatusOK, tt.reply
+			})
+
+			story := Story{ID: hnID(1), Title: "Title", URL: "https://example.com/"}
+			insight, err := analyzeWithOllama(context.Background(), Config{Focus: "cybersecurity", Lang: tt.lang}, story)
+			if err != nil {
+				t.Fatalf("analyzeWithOllama() error = %v", err)
+			}
+			instruction := languageInstruction(tt.lang)
+			if asked := instruction != "" && strings.Contains(prompt, instruction); asked != tt.wantAsked {
+				t.Errorf("prompt asks for %q = %v, want %v; prompt:\n%s", tt.lang, asked, tt.wantAsked, prompt)
+			}
+			if priorityLevel(insight.Priority) != tt.want.Priority || insight.Summary != tt.want.Summary {
+				t.Errorf("analyzeWithOllama() = %q, %q; want %q, %q", insight.Priority, insight.Summary, tt.want.Priority, tt.want.Summary)
+			}
+		})
+	}
+}
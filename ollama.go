@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// A locally available model as reported by Ollama's /api/tags
+type ollamaModel struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// Fetches the models available on the Ollama server at baseURL
+func listModels(ctx context.Context, baseURL string) ([]ollamaModel, error) {
+	resp, err := httpGet(ctx, strings.TrimRight(baseURL, "/")+"/api/tags")
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach Ollama at %s: %v", baseURL, describeDialError(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := readJSONBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("listing models from %s: %v", baseURL, err)
+	}
+
+	var tags struct {
+		Models []ollamaModel `json:"models"`
+	}
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, fmt.Errorf("decoding model list from %s: %v", baseURL, err)
+	}
+
+	return tags.Models, nil
+}
+
+// Runs model on prompt with the Ollama server at host through /api/generate
+// and returns the whole response text
+func queryOllama(ctx context.Context, host, model, prompt string) (string, error) {
+	payload, err := json.Marshal(struct {
+		Model  string `json:"model"`
+		Prompt string `json:"prompt"`
+		Stream bool   `json:"stream"`
+	}{model, prompt, false})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(host, "/")+"/api/generate", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("cannot reach Ollama at %s: %v", host, describeDialError(err))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Response string `json:"response"`
+		Error    string `json:"error"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Ollama explains failures in an "error" field
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &result) != nil || result.Error == "" {
+			result.Error = resp.Status
+		}
+		if isModelNotFound(result.Error) {
+			return "", fmt.Errorf("model %s is not available on %s; run `ollama pull %s`", model, host, model)
+		}
+		return "", fmt.Errorf("Ollama at %s: %s", host, result.Error)
+	}
+
+	body, err := readJSONBody(resp)
+	if err != nil {
+		return "", fmt.Errorf("reading response from %s: %v", host, err)
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("decoding response from %s: %v", host, err)
+	}
+	return result.Response, nil
+}
+
+// Returns the version the Ollama server at baseURL reports, such as "0.5.7"
+func serverVersion(ctx context.Context, baseURL string) (string, error) {
+	resp, err := httpGet(ctx, strings.TrimRight(baseURL, "/")+"/api/version")
+	if err != nil {
+		return "", fmt.Errorf("cannot reach Ollama at %s: %v", baseURL, describeDialError(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := readJSONBody(resp)
+	if err != nil {
+		return "", fmt.Errorf("querying version of %s: %v", baseURL, err)
+	}
+	var v struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(body, &v); err != nil || v.Version == "" {
+		return "", fmt.Errorf("%s did not report an Ollama version", baseURL)
+	}
+	return v.Version, nil
+}
+
+// Fails if the server at baseURL reports a version older than minimum. A
+// server that can't be reached, or isn't Ollama and has no version to
+// report, passes; reachability is left to -on-backend-down.
+func checkServerVersion(ctx context.Context, baseURL, minimum string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	v, err := serverVersion(ctx, baseURL)
+	if err != nil {
+		slog.Debug("skipping Ollama version check", "url", baseURL, "err", err)
+		return nil
+	}
+	if compareVersions(v, minimum) < 0 {
+		return fmt.Errorf("Ollama at %s is version %s, older than the %s this tool needs (-min-ollama-version); upgrade it", baseURL, v, minimum)
+	}
+	return nil
+}
+
+// Compares dotted version numbers such as "0.5.7", returning -1, 0 or 1.
+// Pre-release suffixes like "-rc1" are ignored, as are missing or non-numeric
+// parts, which count as 0.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return cmp.Compare(x, y)
+		}
+	}
+	return 0
+}
+
+// Matches the versions -min-ollama-version accepts
+var versionPattern = regexp.MustCompile(`^v?\d+(\.\d+)*$`)
+
+func validVersion(v string) bool {
+	return versionPattern.MatchString(v)
+}
+
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+ "); i >= 0 {
+		v = v[:i]
+	}
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(s)
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// Spells out a failure to connect to any of a host's addresses, which the
+// dialer otherwise reports for the last address tried only. Other errors
+// are returned as they are.
+func describeDialError(err error) error {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "dial" {
+		return err
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	u, perr := url.Parse(urlErr.URL)
+	if perr != nil {
+		return err
+	}
+
+	host := u.Hostname()
+	addrs, lerr := net.DefaultResolver.LookupHost(context.Background(), host)
+	if lerr != nil || len(addrs) == 0 {
+		return err
+	}
+	return fmt.Errorf("no address of %s accepted a connection (tried %s): %v", host, strings.Join(addrs, ", "), opErr.Err)
+}
+
+// Prints models as an aligned table, or as JSON for scripting
+func printModels(w io.Writer, models []ollamaModel, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(models)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSIZE\tMODIFIED")
+	for _, m := range models {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", m.Name, formatBytes(m.Size), m.ModifiedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return tw.Flush()
+}
+
+// Formats a byte count with a binary unit suffix, e.g. "2.0 GB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Starts a fake Ollama server and sends analysis to it for the rest of the
+// test. reply gets each /api/generate prompt and returns the status and the
+// model output or error message.
+func fakeOllama(t *testing.T, reply func(prompt string) (int, string)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		status, text := reply(req.Prompt)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			json.NewEncoder(w).Encode(map[string]any{"response": text, "done": true})
+		} else {
+			json.NewEncoder(w).Encode(map[string]string{"error": text})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	useOllama(t, srv.URL)
+	return srv
+}
+
+// Sends analysis to the Ollama server at url for the rest of the test
+func useOllama(t *testing.T, url string) {
+	saved := ollamaPool.endpoints
+	t.Cleanup(func() { ollamaPool.endpoints = saved })
+	setOllamaEndpoints([]string{url})
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// Analyzes every top story submitted after cfg.Since (or the time recorded
+// in cfg.SinceFile), prints the results like headless mode and returns. Meant
+// for cron jobs processing the delta since their last run.
+func runOnce(ctx context.Context, cfg Config, seen *seenSet) error {
+	start := time.Now()
+
+	if cfg.Since.IsZero() && cfg.SinceFile != "" {
+		since, err := readSinceFile(cfg.SinceFile)
+		if err != nil {
+			return err
+		}
+		cfg.Since = since
+	}
+
+	if err := runCycle(ctx, cfg, seen, math.MaxInt, headlessHandlers(cfg)); err != nil {
+		return err
+	}
+
+	// Only advance the window once the run succeeded
+	if cfg.SinceFile != "" {
+		return os.WriteFile(cfg.SinceFile, []byte(start.Format(time.RFC3339)+"\n"), 0o644)
+	}
+	return nil
+}
+
+// Reads the RFC 3339 timestamp of the last run. A missing file means there
+// was no previous run, so every story is new.
+func readSinceFile(path string) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	since, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return since, nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Forces the selected story's priority to level, keeping the model's
+// original priority on the insight so exports show it was overridden
+func (t *tui) overridePriority(level string) {
+	entry, ok := t.selectedEntry()
+	if !ok || !entry.isStory() {
+		t.flash("[yellow]Select a story to set its priority[-]")
+		return
+	}
+	if entry.Insight.Priority == level {
+		return
+	}
+
+	t.updateStory(entry.Insight.ID, func(e *feedEntry) {
+		if e.Insight.OverriddenFrom == "" {
+			e.Insight.OverriddenFrom = e.Insight.Priority
+		}
+		e.Insight.Priority = level
+	})
+	stats.overrides.Add(1)
+	slog.Info("priority overridden by analyst", "id", entry.Insight.ID, "title", entry.Insight.Title, "from", entry.Insight.Priority, "to", level)
+	t.render()
+	t.flash(fmt.Sprintf("[green]Priority set to %s[-]", level))
+}
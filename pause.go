@@ -0,0 +1,59 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Set while the analyst has paused the model with P. Stories keep being
+// fetched and shown as headlines, and are queued to be analyzed on resume.
+var analysisPaused atomic.Bool
+
+// Stories shown unanalyzed while analysis was paused, oldest first
+var pausedBacklog struct {
+	mu      sync.Mutex
+	stories []Story
+	max     int // Set from -pause-backlog at startup
+}
+
+// Queues a story for analysis once the pause ends. Stories beyond the cap
+// stay as headlines.
+func queuePaused(story Story) {
+	pausedBacklog.mu.Lock()
+	defer pausedBacklog.mu.Unlock()
+	if len(pausedBacklog.stories) < pausedBacklog.max {
+		pausedBacklog.stories = append(pausedBacklog.stories, story)
+	}
+}
+
+// Returns and clears the queued stories
+func takePaused() []Story {
+	pausedBacklog.mu.Lock()
+	defer pausedBacklog.mu.Unlock()
+	stories := pausedBacklog.stories
+	pausedBacklog.stories = nil
+	return stories
+}
+
+// Number of stories waiting for the pause to end
+func pausedCount() int {
+	pausedBacklog.mu.Lock()
+	defer pausedBacklog.mu.Unlock()
+	return len(pausedBacklog.stories)
+}
+
+// Pauses or resumes analysis. Resuming starts a cycle right away so the
+// backlog doesn't wait for the next poll.
+func (t *tui) togglePauseAnalysis() {
+	if analysisPaused.Load() {
+		analysisPaused.Store(false)
+		t.flash("[green]Analysis resumed[-]")
+		select {
+		case t.refresh <- struct{}{}:
+		default: // A cycle is already pending
+		}
+		return
+	}
+	analysisPaused.Store(true)
+	t.flash("[yellow]Analysis paused; headlines keep arriving[-]")
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Priority levels the model is asked to choose from
+const (
+	priorityHigh   = "High"
+	priorityMedium = "Medium"
+	priorityLow    = "Low"
+)
+
+// A level after a "Priority" label, as in "Priority: **High**", and a level
+// as a word of its own, so "highlights" or "below" don't count
+var (
+	labeledPriority = regexp.MustCompile(`(?i)\bpriority\W{0,10}(high|medium|low)\b`)
+	priorityWord    = regexp.MustCompile(`(?i)\b(high|medium|low)\b`)
+)
+
+// Extracts the priority level from free-form model output such as
+// "Priority: **High**", returning "" when no level is mentioned. A labeled
+// level wins; otherwise the first level mentioned is taken.
+func priorityLevel(text string) string {
+	m := labeledPriority.FindStringSubmatch(text)
+	if m == nil {
+		m = priorityWord.FindStringSubmatch(text)
+	}
+	if m == nil {
+		return ""
+	}
+	switch strings.ToLower(m[1]) {
+	case "high":
+		return priorityHigh
+	case "medium":
+		return priorityMedium
+	default:
+		return priorityLow
+	}
+}
+
+// Orders priority levels for comparison: High ranks above Medium above Low,
+// and text without a level ranks lowest
+func priorityRank(text string) int {
+	switch priorityLevel(text) {
+	case priorityHigh:
+		return 3
+	case priorityMedium:
+		return 2
+	case priorityLow:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestPriorityLevel(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"High", priorityHigh},
+		{"Priority: **High**", priorityHigh},
+		{"priority - medium, though the impact is high", priorityMedium},
+		{"LOW", priorityLow},
+		{"This highlights a low risk", priorityLow},
+		{"Highly relevant, medium urgency", priorityMedium},
+		{"Follow the link below", ""},
+		{"highlights", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := priorityLevel(tt.text); got != tt.want {
+			t.Errorf("priorityLevel(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Marks the selected story read, or unread if it already is, together with
+// any other entries for the same URL. On a collapsed Low-priority group x
+// expands it instead.
+func (t *tui) toggleReadSelected() {
+	t.mu.Lock()
+	if t.selected >= 0 && t.selected < len(t.entries) && t.entries[t.selected].Group != nil {
+		t.mu.Unlock()
+		t.expandSelected()
+		return
+	}
+	if t.selected < 0 || t.selected >= len(t.entries) || !t.entries[t.selected].isStory() {
+		t.mu.Unlock()
+		t.flash("[yellow]Select a story to mark it read[-]")
+		return
+	}
+
+	key := tagKey(t.entries[t.selected].Insight)
+	read := !t.read[key]
+	if read {
+		t.read[key] = true
+	} else {
+		delete(t.read, key)
+	}
+	for i := range t.entries {
+		if t.entries[i].isStory() && tagKey(t.entries[i].Insight) == key {
+			t.entries[i].Read = read
+		}
+	}
+	if t.hideRead && read {
+		// Move on to the next story rather than the top of the feed
+		visible := t.visibleIndices()
+		if n := slices.Index(visible, t.selected); n >= 0 && n+1 < len(visible) {
+			t.selected = visible[n+1]
+		}
+		t.selectVisible()
+	}
+	t.mu.Unlock()
+
+	t.render()
+}
+
+// Shows or hides the entries marked read
+func (t *tui) toggleHideRead() {
+	t.mu.Lock()
+	t.hideRead = !t.hideRead
+	hidden := t.hideRead
+	t.selectVisible()
+	t.mu.Unlock()
+
+	t.render()
+	if hidden {
+		t.flash("Hiding read entries")
+	} else {
+		t.flash("Showing read entries")
+	}
+}
+
+// Number of stories in the feed not yet marked read. The caller must hold
+// t.mu.
+func (t *tui) unreadCount() int {
+	n := 0
+	for _, entry := range t.entries {
+		if entry.isStory() && !entry.Read {
+			n++
+		}
+		n += len(entry.Group) // Collapsed entries can't be marked read
+	}
+	return n
+}
+
+// Describes the read state for the feed title, once anything has been read
+// this session or a previous one. The caller must hold t.mu.
+func (t *tui) readSummary() string {
+	if len(t.read) == 0 {
+		return ""
+	}
+	summary := fmt.Sprintf("%d unread", t.unreadCount())
+	if t.hideRead {
+		summary += ", read hidden"
+	}
+	return summary
+}
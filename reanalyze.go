@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Runs a fresh analysis of the selected story in the background and replaces
+// the entry with the result, keeping its place and incident tag
+func (t *tui) reanalyzeSelected() {
+	entry, ok := t.selectedEntry()
+	if !ok {
+		t.flash("[yellow]No entry selected[-]")
+		return
+	}
+	if !entry.isStory() {
+		t.flash("[yellow]Only stories can be re-analyzed[-]")
+		return
+	}
+	if entry.Reanalyzing {
+		return
+	}
+
+	t.updateStory(entry.Insight.ID, func(e *feedEntry) { e.Reanalyzing = true })
+	t.render()
+
+	go func() {
+		err := t.reanalyze(entry.Insight)
+		t.app.QueueUpdateDraw(func() {
+			if err != nil {
+				t.flash(fmt.Sprintf("[red]Re-analysis failed: %v[-]", err))
+			} else {
+				t.flash("[green]Re-analyzed[-]")
+			}
+		})
+		t.render()
+	}()
+}
+
+// Re-analyzes every story currently shown in the feed after confirmation,
+// with up to -analyze-workers running at once and progress in the status bar
+func (t *tui) reanalyzeAll() {
+	t.mu.Lock()
+	var insights []HighValueInsight
+	for _, i := range t.visibleIndices() {
+		if entry := t.entries[i]; entry.isStory() && !entry.Reanalyzing {
+			insights = append(insights, entry.Insight)
+		}
+	}
+	t.mu.Unlock()
+	if len(insights) == 0 {
+		t.flash("[yellow]No stories to re-analyze[-]")
+		return
+	}
+
+	t.confirm(fmt.Sprintf("Re-analyze all %d displayed stories? This runs the model on each.", len(insights)), func() {
+		for _, insight := range insights {
+			t.updateStory(insight.ID, func(e *feedEntry) { e.Reanalyzing = true })
+		}
+		t.render()
+
+		go func() {
+			var (
+				wg       sync.WaitGroup
+				workers  = make(chan struct{}, t.cfg.AnalyzeWorkers)
+				finished atomic.Int64
+				failed   atomic.Int64
+			)
+			for _, insight := range insights {
+				wg.Add(1)
+				workers <- struct{}{}
+				go func() {
+					defer func() {
+						<-workers
+						wg.Done()
+					}()
+					if t.reanalyze(insight) != nil {
+						failed.Add(1)
+					}
+					progress := fmt.Sprintf("Re-analyzing: %d/%d done", finished.Add(1), len(insights))
+					t.app.QueueUpdateDraw(func() { t.statusView.SetText(progress) })
+					t.render()
+				}()
+			}
+			wg.Wait()
+
+			t.app.QueueUpdateDraw(func() {
+				if n := failed.Load(); n > 0 {
+					t.flash(fmt.Sprintf("[red]Re-analyzed %d stories, %d failed[-]", len(insights), n))
+				} else {
+					t.flash(fmt.Sprintf("[green]Re-analyzed %d stories[-]", len(insights)))
+				}
+			})
+		}()
+	})
+}
+
+// Analyzes the insight's story again and puts the result in its feed entry,
+// clearing the entry's re-analyzing marker either way
+func (t *tui) reanalyze(insight HighValueInsight) error {
+	// Refetch HN stories so the engagement boost sees current numbers; a
+	// text-only copy of the story is enough for the model otherwise
+	id := insight.ID
+	story := Story{ID: id, Title: insight.Title, URL: insight.URL, DiscussionURL: insight.DiscussionURL}
+	if item, ok := id.hnItem(); ok {
+		if fresh, err := fetchStoryDetails(t.ctx, item); err == nil {
+			story = fresh
+		}
+	}
+
+	fresh, err := analyzeStory(t.ctx, t.cfg, story)
+	t.updateStory(id, func(e *feedEntry) {
+		e.Reanalyzing = false
+		if err == nil {
+			fresh.Tag = e.Insight.Tag
+			e.Insight = fresh
+		}
+	})
+	return err
+}
+
+// Applies fn to the feed entry for the story with the given ID, if it's
+// still in the feed
+func (t *tui) updateStory(id storyID, fn func(*feedEntry)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	i := slices.IndexFunc(t.entries, func(entry feedEntry) bool {
+		return entry.isStory() && entry.Insight.ID == id
+	})
+	if i >= 0 {
+		fn(&t.entries[i])
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Titles of recently fetched stories, for spotting reposts that come back
+// under a new ID and URL
+type titleWindow struct {
+	mu     sync.Mutex
+	window time.Duration
+	titles map[string]titleSeen // By normalized title
+}
+
+type titleSeen struct {
+	ID storyID
+	At time.Time
+}
+
+var recentTitles = &titleWindow{titles: make(map[string]titleSeen)}
+
+// Records the story's title and returns the ID of an earlier story with the
+// same title within the window, or "" if it's the first
+func (w *titleWindow) repostOf(story Story, now time.Time) storyID {
+	key := strings.ToLower(strings.Join(strings.Fields(story.Title), " "))
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for title, seen := range w.titles {
+		if now.Sub(seen.At) > w.window {
+			delete(w.titles, title)
+		}
+	}
+
+	if seen, ok := w.titles[key]; ok && seen.ID != story.ID {
+		return seen.ID
+	}
+	w.titles[key] = titleSeen{ID: story.ID, At: now}
+	return ""
+}
+
+// Applies -repost-policy to freshly fetched stories, returning those still
+// to be analyzed. Skipped reposts are reported like other filtered stories;
+// merged ones go to h.Repost so the earlier entry can be updated.
+func filterReposts(cfg Config, stories []Story, h feedHandlers) []Story {
+	if cfg.RepostPolicy == "show" {
+		return stories
+	}
+
+	kept := stories[:0]
+	for _, story := range stories {
+		original := recentTitles.repostOf(story, time.Now())
+		switch {
+		case original == "":
+			kept = append(kept, story)
+		case cfg.RepostPolicy == "merge" && h.Repost != nil:
+			h.Repost(story, original)
+		default:
+			h.skip(cfg, story, "repost of a recent title")
+		}
+	}
+	return kept
+}
+
+// Folds a repost into the entry for the original story: the entry links to
+// the newest discussion and counts the resubmissions
+func (t *tui) mergeRepost(story Story, originalID storyID) {
+	t.updateStory(originalID, func(e *feedEntry) {
+		e.Reposts++
+		if story.DiscussionURL != "" {
+			e.Insight.DiscussionURL = story.DiscussionURL
+		}
+	})
+	t.render()
+}
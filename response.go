@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// The JSON object the analysis prompt asks the model to reply with
+type modelReply struct {
+	Priority   string          `json:"priority"`
+	Summary    string          `json:"summary"`
+	Rationale  string          `json:"rationale"`
+	Confidence json.RawMessage `json:"confidence"` // A number, though models sometimes quote it
+}
+
+// Decodes the model's JSON reply, tolerating text or a markdown code fence
+// around the object. It takes the first complete object that decodes, and
+// fails if there's none or its priority names no known level; the priority
+// is normalized to that level.
+func parseModelReply(output string) (modelReply, bool) {
+	var reply modelReply
+	for rest := output; ; {
+		object, after, ok := nextJSONObject(rest)
+		if !ok {
+			return modelReply{}, false
+		}
+		if json.Unmarshal([]byte(object), &reply) == nil {
+			break
+		}
+		reply, rest = modelReply{}, after
+	}
+	reply.Priority = priorityLevel(reply.Priority)
+	if reply.Priority == "" {
+		return modelReply{}, false
+	}
+	reply.Summary = strings.TrimSpace(reply.Summary)
+	reply.Rationale = strings.TrimSpace(reply.Rationale)
+	return reply, true
+}
+
+// Finds the first balanced {…} span in text, skipping braces inside JSON
+// string literals, and returns it with the text after its opening brace so
+// the caller can look further if it doesn't decode
+func nextJSONObject(text string) (object, rest string, ok bool) {
+	start := strings.IndexByte(text, '{')
+	if start < 0 {
+		return "", "", false
+	}
+	depth, inString, escaped := 0, false, false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return text[start : i+1], text[start+1:], true
+			}
+		}
+	}
+	// Unbalanced from here; an object may still start further on
+	return nextJSONObject(text[start+1:])
+}
+
+// Returns the reply's confidence from 0 to 1, or neutralConfidence if it
+// gave none that parses
+func (r modelReply) confidence() float64 {
+	return parseConfidence(strings.Trim(string(r.Confidence), `"`))
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestParseModelReply(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		wantOK       bool
+		wantPriority string
+		wantSummary  string
+	}{
+		{"bare object", `{"priority": "High", "summary": "S"}`, true, priorityHigh, "S"},
+		{"prose with braces after", `{"priority": "Low", "summary": "S"} Note: {see above}`, true, priorityLow, "S"},
+		{"braces inside strings", `{"priority": "Medium", "summary": "uses {} and \"}\" in text"}`, true, priorityMedium, `uses {} and "}" in text`},
+		{"prose object first", "{placeholder}\n```json\n{\"priority\": \"High\", \"summary\": \"S\"}\n```", true, priorityHigh, "S"},
+		{"nested object", `{"priority": "High", "summary": "S", "extra": {"a": 1}}`, true, priorityHigh, "S"},
+		{"unbalanced", `{"priority": "High", "summary": "S"`, false, "", ""},
+		{"no object", "Priority: High", false, "", ""},
+		{"unknown level", `{"priority": "urgent", "summary": "S"}`, false, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply, ok := parseModelReply(tt.output)
+			if ok != tt.wantOK || reply.Priority != tt.wantPriority || reply.Summary != tt.wantSummary {
+				t.Errorf("parseModelReply() = %q, %q, %v; want %q, %q, %v", reply.Priority, reply.Summary, ok, tt.wantPriority, tt.wantSummary, tt.wantOK)
+			}
+		})
+	}
+}
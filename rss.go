@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Feeds rarely change by the minute, so each is fetched at most this often
+const rssMinInterval = 5 * time.Minute
+
+// An RSS 2.0 or Atom feed given with -rss
+type rssSource struct {
+	url       string
+	lastFetch time.Time // Only touched by the poll loop
+}
+
+func (s *rssSource) Name() string { return "RSS " + feedHost(s.url) }
+func (s *rssSource) Due() bool    { return time.Since(s.lastFetch) >= rssMinInterval }
+
+func (s *rssSource) Fetch(ctx context.Context, cfg Config, seen *seenSet, limit int, skip func(Story, string)) ([]Story, error) {
+	s.lastFetch = time.Now()
+	body, _, err := fetchBody(ctx, s.url, readFeedBody)
+	if err != nil {
+		return nil, err
+	}
+	all, err := parseFeed(body)
+	if err != nil {
+		return nil, err
+	}
+	return takeUnseen(cfg, seen, all, limit, skip), nil
+}
+
+// The parts of an RSS 2.0 or Atom document the feed needs. Both are decoded
+// into one struct since their elements don't overlap.
+type feedDocument struct {
+	Items []struct {
+		Title   string `xml:"title"`
+		Link    string `xml:"link"`
+		GUID    string `xml:"guid"`
+		PubDate string `xml:"pubDate"`
+		// Usually the discussion page on aggregators such as Reddit
+		Comments string `xml:"comments"`
+	} `xml:"channel>item"`
+	Entries []struct {
+		Title string `xml:"title"`
+		ID    string `xml:"id"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// Decodes the items of an RSS 2.0 or Atom feed, newest first as feeds list
+// them, skipping those without a title or link
+func parseFeed(data []byte) ([]Story, error) {
+	var doc feedDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decoding feed: %v", err)
+	}
+
+	var stories []Story
+	add := func(key, title, link, discussion string, published time.Time) {
+		title = strings.Join(strings.Fields(title), " ")
+		link = strings.TrimSpace(link)
+		if title == "" || link == "" {
+			return
+		}
+		if key == "" {
+			key = link
+		}
+		story := Story{ID: sourceID("rss", key), Title: title, URL: link, DiscussionURL: strings.TrimSpace(discussion)}
+		if !published.IsZero() {
+			story.Time = published.Unix()
+		}
+		stories = append(stories, story)
+	}
+	for _, item := range doc.Items {
+		add(item.GUID, item.Title, item.Link, item.Comments, parseFeedTime(item.PubDate))
+	}
+	for _, entry := range doc.Entries {
+		link := ""
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+		add(entry.ID, entry.Title, link, "", parseFeedTime(published))
+	}
+	return stories, nil
+}
+
+// Parses the RFC 1123 dates of RSS and the RFC 3339 ones of Atom, returning
+// the zero time for anything else
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Reads a feed response body, up to -max-body-bytes. Feeds are served with
+// too many content types to check one.
+func readFeedBody(resp *http.Response) ([]byte, error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, &responseError{
+			StatusCode: resp.StatusCode,
+			msg:        fmt.Sprintf("unexpected status %d from %s", resp.StatusCode, resp.Request.URL),
+		}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBodyBytes {
+		return nil, fmt.Errorf("response too large: %s sent more than %d bytes (-max-body-bytes)", resp.Request.URL, maxBodyBytes)
+	}
+	return body, nil
+}
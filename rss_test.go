@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+		want []Story
+	}{
+		{
+			"RSS 2.0",
+			`<?xml version="1.0"?>
+<rss version="2.0"><channel><title>r/netsec</title>
+<item><title>  New   exploit </title><link>https://example.com/1</link><guid>t3_abc</guid>
+<pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate><comments>https://reddit.com/c/1</comments></item>
+<item><title>No GUID</title><link>https://example.com/2</link></item>
+<item><title></title><link>https://example.com/untitled</link></item>
+</channel></rss>`,
+			[]Story{
+				{ID: "rss:t3_abc", Title: "New exploit", URL: "https://example.com/1", Time: time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC).Unix(), DiscussionURL: "https://reddit.com/c/1"},
+				{ID: "rss:https://example.com/2", Title: "No GUID", URL: "https://example.com/2"},
+			},
+		},
+		{
+			"Atom",
+			`<feed xmlns="http://www.w3.org/2005/Atom">
+<entry><title>Advisory</title><id>urn:uuid:1</id>
+<link rel="self" href="https://example.com/self"/><link rel="alternate" href="https://example.com/a"/>
+<updated>2024-01-02T03:04:05Z</updated></entry>
+<entry><title>No link</title><id>urn:uuid:2</id></entry>
+</feed>`,
+			[]Story{
+				{ID: "rss:urn:uuid:1", Title: "Advisory", URL: "https://example.com/a", Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Unix()},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFeed([]byte(tt.feed))
+			if err != nil {
+				t.Fatalf("parseFeed() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseFeed() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("story %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := parseFeed([]byte("<html><body>Not a feed")); err == nil {
+		t.Error("parseFeed() of broken XML: error = nil, want an error")
+	}
+}
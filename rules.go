@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// A priority override from the -rules file: stories matching every
+// condition get Priority, whatever the model said. Empty conditions match
+// anything.
+type rule struct {
+	Text     string // The rule as written, recorded on insights it fires for
+	Priority string
+	Source   string // "hn", "lobsters" or "rss"
+	Domain   string // Matches the domain and its subdomains
+	Keyword  string // Lowercased; matched anywhere in the title
+}
+
+// Loads priority override rules from a file with one rule per line, such as
+//
+//	High: keyword=zero-day domain=googleprojectzero.blogspot.com
+//
+// Conditions are source=, domain= and keyword=; quote a keyword containing
+// spaces. Blank lines and lines starting with # are ignored.
+func loadRules(path string) ([]rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []rule
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := parseRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, n+1, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Parses a single "Priority: condition ..." rule
+func parseRule(line string) (rule, error) {
+	priority, conditions, ok := strings.Cut(line, ":")
+	if !ok {
+		return rule{}, fmt.Errorf("expected Priority: conditions, got %q", line)
+	}
+	r := rule{Text: line, Priority: priorityLevel(strings.TrimSpace(priority))}
+	if r.Priority == "" {
+		return rule{}, fmt.Errorf("priority must be High, Medium or Low, got %q", strings.TrimSpace(priority))
+	}
+
+	for _, field := range splitConditions(conditions) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return rule{}, fmt.Errorf("expected key=value, got %q", field)
+		}
+		switch key {
+		case "source":
+			if value != "hn" && value != "lobsters" && value != "rss" {
+				return rule{}, fmt.Errorf("source must be hn, lobsters or rss, got %q", value)
+			}
+			r.Source = value
+		case "domain":
+			r.Domain = strings.TrimPrefix(strings.ToLower(value), "www.")
+		case "keyword":
+			r.Keyword = strings.ToLower(value)
+		default:
+			return rule{}, fmt.Errorf("unknown condition %q; use source, domain or keyword", key)
+		}
+	}
+	if r.Source == "" && r.Domain == "" && r.Keyword == "" {
+		return rule{}, fmt.Errorf("rule has no conditions")
+	}
+	return r, nil
+}
+
+// Splits conditions on whitespace, keeping double-quoted values together
+func splitConditions(s string) []string {
+	var fields []string
+	var field strings.Builder
+	quoted := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t'):
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// Reports whether the insight's story meets every condition of the rule
+func (r rule) matches(insight HighValueInsight) bool {
+	if r.Source != "" && r.Source != sourceOf(insight.ID) {
+		return false
+	}
+	if r.Domain != "" {
+		domain := domainOf(insight.URL)
+		if domain != r.Domain && !strings.HasSuffix(domain, "."+r.Domain) {
+			return false
+		}
+	}
+	return r.Keyword == "" || strings.Contains(strings.ToLower(insight.Title), r.Keyword)
+}
+
+// Forces the priority of the first rule the insight matches, in file order,
+// and records the rule on the insight. Insights matching none are unchanged.
+func applyRules(insight HighValueInsight, rules []rule) HighValueInsight {
+	for _, r := range rules {
+		if r.matches(insight) {
+			insight.Priority = r.Priority
+			insight.Rule = r.Text
+			return insight
+		}
+	}
+	return insight
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyRules(t *testing.T) {
+	rules := mustParseRules(t,
+		`High: keyword=zero-day`,
+		`Low: domain=example.com`,
+		`Medium: source=lobsters`,
+		`High: source=rss keyword="data breach"`,
+	)
+
+	tests := []struct {
+		name     string
+		insight  HighValueInsight
+		wantRule string // "" when no rule should fire
+	}{
+		{"keyword anywhere in the title", HighValueInsight{ID: hnID(1), Title: "Chrome Zero-Day exploited", URL: "https://blog.example.org/"}, `High: keyword=zero-day`},
+		{"first match wins", HighValueInsight{ID: hnID(1), Title: "Zero-day in widget", URL: "https://example.com/x"}, `High: keyword=zero-day`},
+		{"domain", HighValueInsight{ID: hnID(1), Title: "Release notes", URL: "https://example.com/notes"}, `Low: domain=example.com`},
+		{"subdomain", HighValueInsight{ID: hnID(1), Title: "Release notes", URL: "https://blog.example.com/notes"}, `Low: domain=example.com`},
+		{"www prefix", HighValueInsight{ID: hnID(1), Title: "Release notes", URL: "https://www.example.com/"}, `Low: domain=example.com`},
+		{"lookalike domain", HighValueInsight{ID: hnID(1), Title: "Release notes", URL: "https://notexample.com/"}, ""},
+		{"source", HighValueInsight{ID: sourceID("lobsters", "abc"), Title: "Rust 2.0", URL: "https://rust-lang.org/"}, `Medium: source=lobsters`},
+		{"all conditions must match", HighValueInsight{ID: hnID(1), Title: "Big data breach", URL: "https://news.org/"}, ""},
+		{"quoted keyword", HighValueInsight{ID: sourceID("rss", "g"), Title: "Retailer confirms data breach", URL: "https://news.org/"}, `High: source=rss keyword="data breach"`},
+		{"no source matches no source rule", HighValueInsight{Title: "Rust 2.0", URL: "https://rust-lang.org/"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.insight
+			in.Priority = "Medium"
+			got := applyRules(in, rules)
+			if got.Rule != tt.wantRule {
+				t.Fatalf("applyRules() rule = %q, want %q", got.Rule, tt.wantRule)
+			}
+			want := "Medium"
+			if tt.wantRule != "" {
+				want, _, _ = strings.Cut(tt.wantRule, ":")
+			}
+			if got.Priority != want {
+				t.Errorf("applyRules() priority = %q, want %q", got.Priority, want)
+			}
+		})
+	}
+}
+
+func TestLoadRulesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"bad priority", "# comment\nHigh: keyword=a\n\nUrgent: keyword=b\n", "rules.txt:4: priority must be High, Medium or Low"},
+		{"missing colon", "High keyword=a\n", "rules.txt:1: expected Priority: conditions"},
+		{"unknown condition", "High: keyword=a\nLow: author=b\n", "rules.txt:2: unknown condition \"author\""},
+		{"bad source", "Low: source=reddit\n", "rules.txt:1: source must be hn, lobsters or rss"},
+		{"no conditions", "Low:\n", "rules.txt:1: rule has no conditions"},
+		{"empty value", "Low: domain=\n", "rules.txt:1: expected key=value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.txt")
+			if err := os.WriteFile(path, []byte(tt.file), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadRules(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("loadRules() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// Parses rules written inline, failing the test on any error
+func mustParseRules(t *testing.T, lines ...string) []rule {
+	t.Helper()
+	var rules []rule
+	for _, line := range lines {
+		r, err := parseRule(line)
+		if err != nil {
+			t.Fatalf("parseRule(%q) error = %v", line, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
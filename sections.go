@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sections of the grouped view, in display order. Errors, notices,
+// collapsed Low groups, which may mix sources, and stories from no source
+// go under "Other".
+var feedSections = []string{"HN", "Lobste.rs", "RSS", "Other"}
+
+// Returns the section an entry is shown under in the grouped view
+func entrySection(entry feedEntry) string {
+	if !entry.isStory() && entry.SkipReason == "" {
+		return "Other"
+	}
+	switch sourceOf(entry.Insight.ID) {
+	case "hn":
+		return "HN"
+	case "lobsters":
+		return "Lobste.rs"
+	case "rss":
+		return "RSS"
+	default:
+		return "Other"
+	}
+}
+
+// Reports whether the feed is shown grouped by source. With a single
+// source the flat feed is used whatever the toggle says. The caller must
+// hold t.mu.
+func (t *tui) isGrouped() bool {
+	return t.grouped && len(t.cfg.Sources) > 1
+}
+
+// Switches between the flat feed and the view grouped by source
+func (t *tui) toggleGrouped() {
+	if len(t.cfg.Sources) < 2 {
+		t.flash("[yellow]Only one source is active; grouping needs -lobsters or -rss[-]")
+		return
+	}
+	t.mu.Lock()
+	t.grouped = !t.grouped
+	t.selectVisible()
+	t.mu.Unlock()
+
+	t.render()
+}
+
+// Collapses the grouped view's section holding the selected entry; its
+// header stays, and Z brings the entries back
+func (t *tui) collapseSection() {
+	t.mu.Lock()
+	if !t.isGrouped() || t.selected < 0 {
+		t.mu.Unlock()
+		t.flash("[yellow]Select an entry in the grouped view (G) to collapse its section[-]")
+		return
+	}
+	t.collapsed[entrySection(t.entries[t.selected])] = true
+	t.selectVisible()
+	t.mu.Unlock()
+
+	t.render()
+}
+
+// Expands every collapsed section of the grouped view
+func (t *tui) expandSections() {
+	t.mu.Lock()
+	clear(t.collapsed)
+	t.selectVisible()
+	t.mu.Unlock()
+
+	t.render()
+}
+
+// Reorders the visible entry indices section by section, dropping those in
+// collapsed sections. The caller must hold t.mu.
+func (t *tui) sectionOrder(visible []int) []int {
+	ordered := make([]int, 0, len(visible))
+	for _, section := range feedSections {
+		if t.collapsed[section] {
+			continue
+		}
+		for _, i := range visible {
+			if entrySection(t.entries[i]) == section {
+				ordered = append(ordered, i)
+			}
+		}
+	}
+	return ordered
+}
+
+// Builds the grouped view's text: a header per non-empty section followed
+// by its entries, each section faded from newest to oldest on its own. The
+// caller must hold t.mu.
+func (t *tui) groupedText(th theme) string {
+	var b strings.Builder
+	visible := t.filteredIndices()
+	for _, section := range feedSections {
+		var messages []string
+		var indices []int
+		count := 0
+		for _, i := range visible {
+			if entrySection(t.entries[i]) != section {
+				continue
+			}
+			count++
+			if !t.collapsed[section] {
+				indices = append(indices, i)
+				messages = append(messages, fmt.Sprintf(`["%d"]%s[""]`, i, formatEntry(t.entries[i], th, t.cfg, t.compact, t.hideURLs)))
+			}
+		}
+		if count == 0 {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		if t.collapsed[section] {
+			fmt.Fprintf(&b, "[::b]▸ %s[::-] [gray](%d hidden, Z to expand)[-]", section, count)
+			continue
+		}
+		fmt.Fprintf(&b, "[::b]▾ %s[::-] [gray](%d)[-]\n\n", section, count)
+		b.WriteString(t.fadeEntries(indices, messages, th))
+	}
+	return b.String()
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Maximum number of stories remembered as seen; the oldest are forgotten first
+const maxSeenStories = 5000
+
+// A story that has already been shown
+type seenItem struct {
+	ID  storyID `json:"id"`
+	URL string  `json:"url,omitempty"` // Normalized article URL
+
+	// While set, the story is hidden until this Unix time and then forgotten
+	// so it can resurface if it's still on the top list
+	SnoozedUntil int64 `json:"snoozed_until,omitempty"`
+}
+
+// The set of stories already shown, keyed by HN item ID and by normalized URL
+// so the same article isn't shown twice under different IDs
+type seenSet struct {
+	mu    sync.Mutex
+	limit int
+	ids   map[storyID]bool
+	urls  map[string]bool
+	order []seenItem // Oldest first, for eviction
+	dirty bool       // Changed since the last save
+}
+
+// Creates an empty seen-set holding at most limit stories
+func newSeenSet(limit int) *seenSet {
+	return &seenSet{
+		limit: limit,
+		ids:   make(map[storyID]bool),
+		urls:  make(map[string]bool),
+	}
+}
+
+// Reports whether the story ID has been seen
+func (s *seenSet) hasID(id storyID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ids[id]
+}
+
+// Reports whether a story with the same normalized URL has been seen
+func (s *seenSet) hasURL(rawURL string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := normalizeURL(rawURL)
+	return u != "" && s.urls[u]
+}
+
+// Marks a story as seen, evicting the oldest story when over the limit
+func (s *seenSet) add(id storyID, rawURL string) {
+	s.addItem(seenItem{ID: id, URL: normalizeURL(rawURL)})
+}
+
+// Marks an already-normalized item as seen
+func (s *seenSet) addItem(item seenItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ids[item.ID] {
+		return
+	}
+
+	s.ids[item.ID] = true
+	if item.URL != "" {
+		s.urls[item.URL] = true
+	}
+	s.order = append(s.order, item)
+	s.dirty = true
+
+	for len(s.order) > s.limit {
+		oldest := s.order[0]
+		s.order = s.order[1:]
+		delete(s.ids, oldest.ID)
+		if oldest.URL != "" {
+			delete(s.urls, oldest.URL)
+		}
+	}
+}
+
+// Forgets a story so it can be fetched and shown again
+func (s *seenSet) forget(id storyID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ids[id] {
+		return
+	}
+	s.order = slices.DeleteFunc(s.order, func(item seenItem) bool {
+		if item.ID != id {
+			return false
+		}
+		if item.URL != "" {
+			delete(s.urls, item.URL)
+		}
+		return true
+	})
+	delete(s.ids, id)
+	s.dirty = true
+}
+
+// Snoozes a seen story until the given time
+func (s *seenSet) snooze(id storyID, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.order {
+		if s.order[i].ID == id {
+			s.order[i].SnoozedUntil = until.Unix()
+			s.dirty = true
+			return
+		}
+	}
+}
+
+// Forgets stories whose snooze has expired so they can be shown again
+func (s *seenSet) expireSnoozes(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.order = slices.DeleteFunc(s.order, func(item seenItem) bool {
+		if item.SnoozedUntil == 0 || item.SnoozedUntil > now.Unix() {
+			return false
+		}
+		delete(s.ids, item.ID)
+		if item.URL != "" {
+			delete(s.urls, item.URL)
+		}
+		s.dirty = true
+		return true
+	})
+}
+
+// Writes the seen-set to path as JSON, creating its directory if needed
+func (s *seenSet) save(path string) error {
+	// Cleared before writing so stories added meanwhile make it dirty again,
+	// and restored if the write fails so the next save retries it
+	s.mu.Lock()
+	data, err := json.Marshal(s.order)
+	s.dirty = false
+	s.mu.Unlock()
+	if err == nil {
+		err = writeSeenFile(path, data)
+	}
+	if err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func writeSeenFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so a crash can't leave a truncated file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Saves the seen-set if it changed since it was loaded or last saved, so a
+// crash loses at most the last cycle's stories
+func (s *seenSet) saveIfChanged(path string) error {
+	s.mu.Lock()
+	dirty := s.dirty
+	s.mu.Unlock()
+	if !dirty {
+		return nil
+	}
+	return s.save(path)
+}
+
+// Returns where seen stories are kept when -seen-file isn't given: under the
+// user's config directory, or "" if there is none
+func defaultSeenFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "intelstream", "seen.json")
+}
+
+// Loads a seen-set previously written by save. A missing file yields an empty
+// set without error; a corrupt file yields an empty set and the decode error.
+func loadSeenSet(path string, limit int) (*seenSet, error) {
+	s := newSeenSet(limit)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	var items []seenItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return s, err
+	}
+	for _, item := range items {
+		s.addItem(item)
+	}
+	s.dirty = false
+
+	return s, nil
+}
+
+// Normalizes a URL for duplicate detection by lowercasing the host, dropping
+// "www.", the fragment, tracking parameters and any trailing slash
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(key, "utm_") {
+			query.Del(key)
+		}
+	}
+
+	normalized := host + strings.TrimSuffix(u.EscapedPath(), "/")
+	if encoded := query.Encode(); encoded != "" {
+		normalized += "?" + encoded
+	}
+	return normalized
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSeenSetSaveRetriesAfterFailure(t *testing.T) {
+	dir := t.TempDir()
+	// A file where the directory should be makes the first save fail
+	blocker := filepath.Join(dir, "state")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(blocker, "seen.json")
+
+	s := newSeenSet(10)
+	s.add(hnID(1), "https://example.com/")
+	if err := s.saveIfChanged(path); err == nil {
+		t.Fatal("saveIfChanged() error = nil, want a write error")
+	}
+
+	if err := os.Remove(blocker); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.saveIfChanged(path); err != nil {
+		t.Fatalf("saveIfChanged() retry error = %v", err)
+	}
+	loaded, err := loadSeenSet(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.hasID(hnID(1)) {
+		t.Error("saved seen-set lost story 1 after the failed save")
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !unix
+
+package main
+
+// SIGUSR1 doesn't exist outside Unix, so dumping on a signal is a no-op
+func onDumpSignal(fn func()) {}
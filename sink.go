@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// An extra destination every successfully analyzed insight is sent to, on
+// top of the TUI or headless output. Emit may be called from the poll loop
+// and must be safe for concurrent use.
+type Sink interface {
+	Emit(HighValueInsight) error
+}
+
+// Sends each insight to every sink in turn. A failing sink is logged and
+// doesn't stop the others.
+type multiSink []namedSink
+
+type namedSink struct {
+	name string // The -sink value, for logs
+	Sink
+}
+
+func (m multiSink) Emit(insight HighValueInsight) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Emit(insight); err != nil {
+			slog.Error("sink failed", "sink", s.name, "err", err)
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Closes the sinks that need it, such as -webhook's and -log-file's, which
+// finish what's still queued first
+func (m multiSink) Close() error {
+	var errs []error
+	for _, s := range m {
+		if c, ok := s.Sink.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Writes each insight as a versioned JSON record per line
+type jsonlSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *jsonlSink) Emit(insight HighValueInsight) error {
+	data, err := json.Marshal(newInsightRecord(insight))
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// Opens the sink described by a -sink value: "stdout" or "jsonl:PATH",
+// where PATH is appended to
+func openSink(spec string) (Sink, error) {
+	kind, path, err := parseSinkSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "stdout" {
+		return &jsonlSink{w: os.Stdout}, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("-sink %q: %v", spec, err)
+	}
+	return &jsonlSink{w: f}, nil
+}
+
+// Splits a -sink value into its kind and, for jsonl, the file path
+func parseSinkSpec(spec string) (kind, path string, err error) {
+	kind, path, _ = strings.Cut(spec, ":")
+	switch kind {
+	case "stdout":
+		return kind, "", nil
+	case "jsonl":
+		if path == "" {
+			return "", "", fmt.Errorf("-sink %q: missing file path", spec)
+		}
+		return kind, path, nil
+	}
+	return "", "", fmt.Errorf("-sink %q: unknown sink, want stdout or jsonl:PATH", spec)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Prompts for a duration and hides the selected entry until it passes
+func (t *tui) snoozeSelected() {
+	entry, ok := t.selectedEntry()
+	if !ok {
+		t.flash("[yellow]No entry selected[-]")
+		return
+	}
+	if !entry.isStory() {
+		t.flash("[yellow]Only stories can be snoozed[-]")
+		return
+	}
+
+	t.prompt("Snooze for: ", "1h", func(text string) {
+		d, err := time.ParseDuration(text)
+		if err != nil || d <= 0 {
+			t.flash(fmt.Sprintf("[red]Invalid duration %q[-]", text))
+			return
+		}
+
+		t.seen.snooze(entry.Insight.ID, time.Now().Add(d))
+		t.removeStory(entry.Insight.ID)
+		t.flash(fmt.Sprintf("[green]Snoozed for %v[-]", d))
+	})
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// A place stories are fetched from. Fetch returns up to limit stories not
+// yet in seen, marking them seen and passing reposts of seen URLs and stories
+// too old for -max-age or -since to skip.
+type Source interface {
+	Name() string // Shown in the status bar and fetch errors
+	Due() bool    // Whether a fetch is allowed this cycle, for sources that ask for a low request rate
+	Fetch(ctx context.Context, cfg Config, seen *seenSet, limit int, skip func(Story, string)) ([]Story, error)
+}
+
+// Identifies a story across sources: an HN item ID in decimal, or the
+// -rules source name and the source's own ID, as in "lobsters:abc123" or
+// "rss:<guid>". Empty for stories from no source, such as -stdin lines
+// without an id and -analyze-url.
+type storyID string
+
+// Returns the ID of an HN item
+func hnID(item int) storyID {
+	return storyID(strconv.Itoa(item))
+}
+
+// Returns the ID a source gives a story under its own key
+func sourceID(source, key string) storyID {
+	return storyID(source + ":" + key)
+}
+
+// Returns the HN item ID, if the story is from HN
+func (id storyID) hnItem() (int, bool) {
+	item, err := strconv.Atoi(string(id))
+	return item, err == nil && item > 0
+}
+
+// Accepts HN's numeric IDs as well as strings. Seen files from before IDs
+// were strings stored Lobste.rs stories as their negated base-36 short_id,
+// which is turned back into the short_id; 0 meant no ID.
+func (id *storyID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = storyID(s)
+		return nil
+	}
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("story ID must be a string or an integer, got %s", data)
+	}
+	switch {
+	case n > 0:
+		*id = storyID(strconv.FormatInt(n, 10))
+	case n < 0:
+		*id = sourceID("lobsters", strconv.FormatInt(-n, 36))
+	default:
+		*id = ""
+	}
+	return nil
+}
+
+// Returns the -rules source name of the source a story ID came from, or ""
+// for stories from no source
+func sourceOf(id storyID) string {
+	if id == "" {
+		return ""
+	}
+	if source, _, ok := strings.Cut(string(id), ":"); ok {
+		return source
+	}
+	return "hn"
+}
+
+// The sources a cycle fetches from, in order. HN comes first and is the
+// only one whose failures fail the cycle.
+func buildSources(cfg Config) []Source {
+	sources := []Source{hnSource{}}
+	if cfg.Lobsters {
+		sources = append(sources, lobstersSource{})
+	}
+	for _, feedURL := range cfg.RSSFeeds {
+		sources = append(sources, &rssSource{url: feedURL})
+	}
+	return sources
+}
+
+// Hacker News top stories
+type hnSource struct{}
+
+func (hnSource) Name() string { return "HN" }
+func (hnSource) Due() bool    { return true }
+
+func (hnSource) Fetch(ctx context.Context, cfg Config, seen *seenSet, limit int, skip func(Story, string)) ([]Story, error) {
+	return fetchTopStories(ctx, cfg, seen, limit, skip)
+}
+
+// The newest Lobste.rs stories, with -lobsters
+type lobstersSource struct{}
+
+func (lobstersSource) Name() string { return "Lobste.rs" }
+func (lobstersSource) Due() bool    { return lobstersDue() }
+
+func (lobstersSource) Fetch(ctx context.Context, cfg Config, seen *seenSet, limit int, skip func(Story, string)) ([]Story, error) {
+	return fetchNewLobsters(ctx, cfg, seen, limit, skip)
+}
+
+// Keeps the stories not yet seen, up to limit, marking them seen. Reposts
+// of a seen URL and stories failing the age checks are passed to skip;
+// stories without a submission time pass the age checks.
+func takeUnseen(cfg Config, seen *seenSet, all []Story, limit int, skip func(Story, string)) []Story {
+	var stories []Story
+	for _, story := range all {
+		if len(stories) >= limit {
+			break
+		}
+		if seen.hasID(story.ID) {
+			continue
+		}
+		duplicate := seen.hasURL(story.URL)
+		seen.add(story.ID, story.URL)
+		if duplicate {
+			skip(story, "repost of a seen URL")
+			continue
+		}
+		if story.Time != 0 {
+			if reason := tooOld(cfg, story); reason != "" {
+				skip(story, reason)
+				continue
+			}
+		}
+		stories = append(stories, story)
+	}
+	return stories
+}
+
+// Returns the host of a feed URL for naming its source
+func feedHost(feedURL string) string {
+	if u, err := url.Parse(feedURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return feedURL
+}
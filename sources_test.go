@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSourceOf(t *testing.T) {
+	tests := []struct {
+		id   storyID
+		want string
+	}{
+		{hnID(8863), "hn"},
+		{sourceID("lobsters", "abc123"), "lobsters"},
+		{sourceID("rss", "https://example.com/post?id=1"), "rss"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := sourceOf(tt.id); got != tt.want {
+			t.Errorf("sourceOf(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestStoryIDUnmarshal(t *testing.T) {
+	tests := []struct {
+		json string
+		want storyID
+	}{
+		{`8863`, "8863"},
+		{`"8863"`, "8863"},
+		{`"rss:tag:example.com,2024:1"`, "rss:tag:example.com,2024:1"},
+		{`-12345`, "lobsters:9ix"}, // Negated base-36 short_id from older seen files
+		{`0`, ""},
+		{`null`, ""},
+	}
+	for _, tt := range tests {
+		var id storyID
+		if err := json.Unmarshal([]byte(tt.json), &id); err != nil {
+			t.Errorf("unmarshal %s: %v", tt.json, err)
+			continue
+		}
+		if id != tt.want {
+			t.Errorf("unmarshal %s = %q, want %q", tt.json, id, tt.want)
+		}
+	}
+
+	var id storyID
+	if err := json.Unmarshal([]byte(`1.5`), &id); err == nil {
+		t.Errorf("unmarshal 1.5 = %q, want an error", id)
+	}
+}
+
+func TestTakeUnseen(t *testing.T) {
+	seen := newSeenSet(maxSeenStories)
+	seen.add(sourceID("rss", "old"), "https://example.com/old")
+	all := []Story{
+		{ID: sourceID("rss", "old"), Title: "Old", URL: "https://example.com/old"},
+		{ID: sourceID("rss", "repost"), Title: "Repost", URL: "https://example.com/old"},
+		{ID: sourceID("rss", "a"), Title: "A", URL: "https://example.com/a"},
+		{ID: sourceID("rss", "b"), Title: "B", URL: "https://example.com/b"},
+		{ID: sourceID("rss", "c"), Title: "C", URL: "https://example.com/c"},
+	}
+
+	var skipped []string
+	got := takeUnseen(Config{}, seen, all, 2, func(story Story, reason string) { skipped = append(skipped, story.Title) })
+	if len(got) != 2 || got[0].Title != "A" || got[1].Title != "B" {
+		t.Errorf("takeUnseen() = %v, want A and B", got)
+	}
+	if len(skipped) != 1 || skipped[0] != "Repost" {
+		t.Errorf("skipped = %v, want the repost", skipped)
+	}
+	if seen.hasID(sourceID("rss", "c")) {
+		t.Error("story past the limit was marked seen")
+	}
+}
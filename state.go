@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Version of the -state-file format; files with any other version are ignored
+const uiStateVersion = 1
+
+// The parts of the interactive session restored across restarts
+type uiState struct {
+	Version   int               `json:"version"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	TagFilter string            `json:"tag_filter,omitempty"`
+	Theme     string            `json:"theme,omitempty"`
+	Read      []string          `json:"read,omitempty"` // tagKeys of stories marked read
+}
+
+// Loads a state file written by saveUIState. A missing file yields an empty
+// state without error; an unreadable or mismatched file yields an error.
+func loadUIState(path string) (uiState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return uiState{Version: uiStateVersion}, nil
+	}
+	if err != nil {
+		return uiState{}, err
+	}
+
+	var state uiState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return uiState{}, err
+	}
+	if state.Version != uiStateVersion {
+		return uiState{}, fmt.Errorf("unsupported state version %d, want %d", state.Version, uiStateVersion)
+	}
+	return state, nil
+}
+
+// Writes the state atomically, like the seen-story file, creating its
+// directory if needed
+func saveUIState(path string, state uiState) error {
+	state.Version = uiStateVersion
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Applies a restored state to a feed that hasn't started yet. A theme passed
+// with -theme takes precedence over the saved one.
+func (t *tui) restoreState(state uiState) {
+	t.mu.Lock()
+	maps.Copy(t.tags, state.Tags)
+	t.tagFilter = state.TagFilter
+	for _, key := range state.Read {
+		t.read[key] = true
+	}
+	t.mu.Unlock()
+
+	if _, ok := themes[state.Theme]; ok && !t.cfg.ThemeSet {
+		t.setTheme(state.Theme)
+	}
+}
+
+// Captures the state worth restoring on the next launch
+func (t *tui) state() uiState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	read := make([]string, 0, len(t.read))
+	for key := range t.read {
+		read = append(read, key)
+	}
+	slices.Sort(read)
+	return uiState{
+		Tags:      maps.Clone(t.tags),
+		TagFilter: t.tagFilter,
+		Theme:     t.theme,
+		Read:      read,
+	}
+}
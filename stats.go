@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Running counters about the feed, shown in the status bar
+type feedStats struct {
+	errors       atomic.Int64 // Failed fetch cycles, including suppressed ones
+	preFiltered  atomic.Int64 // Stories skipped by the keyword pre-filter
+	cacheHits    atomic.Int64 // HN item lookups served from the item cache
+	scanned      atomic.Int64 // Top-story IDs checked for new stories
+	backoff      atomic.Int64 // Lengthened poll interval in nanoseconds, 0 when not backing off
+	headlineOnly atomic.Int64 // Stories the last cycle showed unanalyzed over -max-pending, 0 when analyzing
+	overrides    atomic.Int64 // Priorities set by hand with 1/2/3
+	latency      latencyWindow
+	throughput   rateWindow
+}
+
+// Number of recent analyses the latency figures are computed over
+const latencySamples = 100
+
+// Durations of the most recent successful analyses
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration // Ring buffer of up to latencySamples
+	next    int
+}
+
+func (w *latencyWindow) add(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.samples) < latencySamples {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % latencySamples
+}
+
+// Returns the mean and 95th percentile of the recorded durations, and false
+// if there are none yet
+func (w *latencyWindow) summary() (avg, p95 time.Duration, ok bool) {
+	w.mu.Lock()
+	sorted := slices.Clone(w.samples)
+	w.mu.Unlock()
+	if len(sorted) == 0 {
+		return 0, 0, false
+	}
+
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return total / time.Duration(len(sorted)), sorted[(len(sorted)*95-1)/100], true
+}
+
+// Window the analysis throughput is measured over
+const throughputWindow = time.Minute
+
+// Most analyses a throughputWindow is expected to hold; beyond this the
+// rate is capped rather than the buffer growing
+const throughputSamples = 1000
+
+// Times of the most recent successful analyses
+type rateWindow struct {
+	mu    sync.Mutex
+	times []time.Time // Ring buffer of up to throughputSamples
+	next  int
+}
+
+func (w *rateWindow) add(t time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.times) < throughputSamples {
+		w.times = append(w.times, t)
+		return
+	}
+	w.times[w.next] = t
+	w.next = (w.next + 1) % throughputSamples
+}
+
+// Returns how many analyses finished in the last throughputWindow, falling
+// back to 0 once the feed goes idle, and false if there have been none yet
+func (w *rateWindow) perWindow(now time.Time) (int, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := 0
+	for _, t := range w.times {
+		if now.Sub(t) < throughputWindow {
+			n++
+		}
+	}
+	return n, len(w.times) > 0
+}
+
+var stats feedStats
+
+// Summarizes the counters for the status bar
+func (s *feedStats) String() string {
+	text := fmt.Sprintf("errors: %d  pre-filtered: %d  cache hits: %d",
+		s.errors.Load(), s.preFiltered.Load(), s.cacheHits.Load())
+	if n := s.overrides.Load(); n > 0 {
+		text += fmt.Sprintf("  overrides: %d", n)
+	}
+	if n, ok := s.throughput.perWindow(time.Now()); ok {
+		text += fmt.Sprintf("  stories/min: %d", n)
+	}
+	if avg, p95, ok := s.latency.summary(); ok {
+		text += fmt.Sprintf("  analysis avg: %v p95: %v", avg.Round(100*time.Millisecond), p95.Round(100*time.Millisecond))
+	}
+	if endpoints := endpointSummary(); endpoints != "" {
+		text += "  ollama: " + endpoints
+	}
+	if d := time.Duration(s.backoff.Load()); d > 0 {
+		text = fmt.Sprintf("[yellow]backing off: polling every %v[-]  ", d) + text
+	}
+	if backendDown.Load() {
+		text = fmt.Sprintf("[red]Ollama unavailable: headlines only (%s)[-]  ", backendMode) + text
+	}
+	if analysisPaused.Load() {
+		text = fmt.Sprintf("[yellow]analysis paused: %d queued[-]  ", pausedCount()) + text
+	}
+	if n := s.headlineOnly.Load(); n > 0 {
+		text = fmt.Sprintf("[yellow]headlines only: %d stories queued[-]  ", n) + text
+	}
+	return text
+}
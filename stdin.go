@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+)
+
+// Largest input line accepted by -stdin
+const maxStdinLine = 1 << 20
+
+// Analyzes JSON-lines items read from r until it's exhausted, printing each
+// result like headless mode. Items get the same pre-filter and age checks as
+// HN stories; lines that don't decode to an item with a title are logged and
+// skipped.
+func runStdin(ctx context.Context, cfg Config, r io.Reader) error {
+	h := headlessHandlers(cfg)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStdinLine)
+	for lineNo := 1; ctx.Err() == nil && scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		story, err := decodeStory(line)
+		if err != nil {
+			slog.Warn("skipping input line", "line", lineNo, "err", err)
+			continue
+		}
+		reason := titleFilter(cfg, story)
+		if story.Time != 0 && reason == "" {
+			reason = tooOld(cfg, story)
+		}
+		if reason != "" {
+			stats.preFiltered.Add(1)
+			h.skip(cfg, story, reason)
+			continue
+		}
+
+		if backendDown.Load() {
+			h.insight(cfg, headlineInsight(story, "Not analyzed: Ollama is unavailable"), nil)
+			continue
+		}
+
+		insight, err := analyzeStory(ctx, cfg, story)
+		if err != nil && ctx.Err() != nil {
+			break // Interrupted, not a failed analysis
+		}
+		if reason := lowConfidence(cfg, insight); err == nil && reason != "" {
+			h.skip(cfg, story, reason)
+			continue
+		}
+		h.insight(cfg, insight, err)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
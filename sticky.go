@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// Makes insight the sticky headline if it ranks at least as high as the
+// current one, so the newest of the most important insights stays on screen
+// after it scrolls out of the feed. Returns the headline's text, or "" if it
+// didn't change. Called with t.mu held; the caller shows the text with
+// showSticky once it has unlocked.
+func (t *tui) offerSticky(insight HighValueInsight) string {
+	rank := priorityRank(insight.Priority)
+	if rank == 0 || (!t.stickyAt.IsZero() && rank < priorityRank(t.sticky.Priority)) {
+		return ""
+	}
+	t.sticky = insight
+	t.stickyAt = time.Now()
+	return fmt.Sprintf("[red::b]▲ %s[-::-] %s", priorityLevel(insight.Priority), tview.Escape(truncateText(insight.Title, t.cfg.TitleWidth)))
+}
+
+// Shows text as the sticky headline. Must not be called with t.mu held, since
+// it waits for the event loop, which takes t.mu itself.
+func (t *tui) showSticky(text string) {
+	t.app.QueueUpdateDraw(func() {
+		t.stickyView.SetText(text)
+		t.layout.ResizeItem(t.stickyView, 1, 0)
+	})
+}
+
+// Hides the sticky headline once it's older than -sticky-ttl. Runs on the
+// event loop.
+func (t *tui) expireSticky() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stickyAt.IsZero() || t.cfg.StickyTTL == 0 || time.Since(t.stickyAt) < t.cfg.StickyTTL {
+		return
+	}
+	t.stickyAt = time.Time{}
+	t.stickyView.Clear()
+	t.layout.ResizeItem(t.stickyView, 0, 0)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// The most recent summaries the model produced, for spotting boilerplate it
+// repeats across unrelated stories
+type summaryWindow struct {
+	mu     sync.Mutex
+	size   int      // Summaries remembered; 0 disables the check
+	recent []string // Normalized, oldest first
+}
+
+// Set up from -summary-window at startup
+var recentSummaries = &summaryWindow{}
+
+// Records summary and reports whether it matches one of the recent ones,
+// ignoring case, spacing and trailing punctuation
+func (w *summaryWindow) repeated(summary string) bool {
+	key := strings.TrimRight(strings.ToLower(strings.Join(strings.Fields(summary), " ")), ".!")
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size == 0 || key == "" {
+		return false
+	}
+
+	for _, s := range w.recent {
+		if s == key {
+			return true
+		}
+	}
+	w.recent = append(w.recent, key)
+	if len(w.recent) > w.size {
+		w.recent = w.recent[1:]
+	}
+	return false
+}
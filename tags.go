@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Returns the key incident tags are stored under: the normalized URL, so a
+// story keeps its tag when it reappears, or the story ID for text posts
+func tagKey(insight HighValueInsight) string {
+	if u := normalizeURL(insight.URL); u != "" {
+		return u
+	}
+	if sourceOf(insight.ID) == "hn" {
+		return "hn:" + string(insight.ID)
+	}
+	return string(insight.ID)
+}
+
+// Prompts for an incident tag and applies it to the selected story and any
+// other entries for the same URL. An empty tag removes it.
+func (t *tui) tagSelected() {
+	entry, ok := t.selectedEntry()
+	if !ok {
+		t.flash("[yellow]No entry selected[-]")
+		return
+	}
+	if !entry.isStory() {
+		t.flash("[yellow]Only stories can be tagged[-]")
+		return
+	}
+
+	t.prompt("Incident tag: ", entry.Insight.Tag, func(text string) {
+		tag := strings.TrimSpace(text)
+		key := tagKey(entry.Insight)
+
+		t.mu.Lock()
+		if tag == "" {
+			delete(t.tags, key)
+		} else {
+			t.tags[key] = tag
+		}
+		for i := range t.entries {
+			if t.entries[i].isStory() && tagKey(t.entries[i].Insight) == key {
+				t.entries[i].Insight.Tag = tag
+			}
+		}
+		t.selectVisible()
+		t.mu.Unlock()
+
+		t.render()
+	})
+}
+
+// Prompts for an incident tag to filter the feed to. An empty tag shows
+// every entry again.
+func (t *tui) filterByTag() {
+	t.mu.Lock()
+	current := t.tagFilter
+	t.mu.Unlock()
+
+	t.prompt("Show only tag: ", current, func(text string) {
+		tag := strings.TrimSpace(text)
+
+		t.mu.Lock()
+		t.tagFilter = tag
+		t.selectVisible()
+		t.mu.Unlock()
+
+		t.render()
+		if tag == "" {
+			t.flash("Showing all entries")
+		} else {
+			t.flash(fmt.Sprintf("Showing only #%s", tag))
+		}
+	})
+}
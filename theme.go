@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// A named color scheme for the feed
+type theme struct {
+	FadeStart string      // Gradient start color; empty with FadeEnd uses the built-in grayscale levels
+	FadeEnd   string      // Gradient end color
+	Priority  string      // Color tag for the priority line
+	Title     string      // Color tag for story titles
+	Border    tcell.Color // Border and title color of the feed box
+}
+
+// Built-in themes, selectable with -theme
+var themes = map[string]theme{
+	"default": {Priority: "[yellow]", Title: "[green]", Border: tcell.ColorWhite},
+	"matrix":  {FadeStart: "#00ff41", FadeEnd: "#003b00", Priority: "[#00ff41]", Title: "[#39ff14]", Border: tcell.ColorGreen},
+	"amber":   {FadeStart: "#ffb000", FadeEnd: "#3d2a00", Priority: "[#ffcc00]", Title: "[#ffb000]", Border: tcell.ColorOrange},
+	"mono":    {FadeStart: "#ffffff", FadeEnd: "#303030", Priority: "[::b]", Title: "[::u]", Border: tcell.ColorGray},
+}
+
+// The order themes are cycled through in the TUI
+var themeNames = []string{"default", "matrix", "amber", "mono"}
+
+// Returns the theme's fade levels, letting -fade-start and -fade-end override
+// its gradient endpoints and -fade-steps set the number of steps
+func (th theme) fade(cfg Config) []string {
+	start, end := th.FadeStart, th.FadeEnd
+	if cfg.FadeStart != "" {
+		start = cfg.FadeStart
+	}
+	if cfg.FadeEnd != "" {
+		end = cfg.FadeEnd
+	}
+	if start == "" && end == "" && cfg.FadeSteps == len(fadeLevels) {
+		return fadeLevels
+	}
+
+	// The flags were validated at startup, so this only fails on a bad theme
+	levels, err := fadeGradient(start, end, cfg.FadeSteps)
+	if err != nil {
+		return fadeLevels
+	}
+	return levels
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Shortens text to at most width runes, cutting at the last word boundary
+// and appending an ellipsis. Width 0 or less disables truncation.
+func truncateText(text string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	if width == 1 {
+		return "…"
+	}
+
+	// Leave room for the ellipsis, then back up to the last space unless the
+	// cut already falls between words
+	runes := []rune(text)
+	cut := string(runes[:width-1])
+	if runes[width-1] != ' ' {
+		if i := strings.LastIndexByte(cut, ' '); i > 0 {
+			cut = cut[:i]
+		}
+	}
+	return strings.TrimRight(cut, " ") + "…"
+}
@@ -0,0 +1,910 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Smallest terminal the feed can be usefully drawn in
+const (
+	minTermWidth  = 40
+	minTermHeight = 10
+)
+
+// Height of the log pane, borders included
+const logPaneHeight = 10
+
+// A single item in the feed: an analyzed story or an operational error
+type feedEntry struct {
+	Insight HighValueInsight
+	Err     error // Set for error entries, which have no story
+	Repeats int   // Identical errors collapsed into this entry after the first
+
+	Cycle int         // Poll cycle the entry arrived in; 0 once it can't be grouped
+	Group []feedEntry // Low-priority entries collapsed into this summary entry
+
+	Reanalyzing bool // Set while a fresh analysis of the story is running
+	Reposts     int  // Resubmissions merged into the entry under -repost-policy merge
+	Read        bool // Marked read with x
+
+	Notice     string // Set for informational entries such as heartbeats
+	SkipReason string // Set for stories shown under -show-skipped instead of analyzed
+
+	AddedAt time.Time // When the entry joined the feed, for -entry-ttl
+}
+
+// Reports whether the entry is a single analyzed story, as opposed to an
+// error, a collapsed group, a notice or a -digest summary, which story
+// actions such as re-analysis and tagging leave alone
+func (e feedEntry) isStory() bool {
+	return e.Err == nil && e.Group == nil && e.Notice == "" && e.SkipReason == "" && !e.Insight.Digest
+}
+
+// The interactive feed and the state behind it
+type tui struct {
+	ctx        context.Context // Done once the TUI is shutting down
+	cfg        Config
+	app        *tview.Application
+	feedView   *tview.TextView
+	statusView *tview.TextView
+	statsView  *tview.TextView
+	stickyView *tview.TextView // Headline for the top-priority insight
+	alertView  *tview.TextView // Banner shown while High insights cluster
+	logView    *tview.TextView // Recent log lines, toggled with L
+	layout     *tview.Flex
+	pages      *tview.Pages
+	bindings   []keyBinding
+	seen       *seenSet
+	refresh    chan struct{} // Wakes the poll loop for an immediate cycle
+
+	mu       sync.Mutex
+	entries  []feedEntry
+	selected int    // Index of the selected entry, or -1 for none
+	theme    string // Name of the active theme
+
+	cycle     int               // Number of the current poll cycle
+	tags      map[string]string // Incident tags keyed by tagKey
+	tagFilter string            // When set, only entries with this tag are shown
+	read      map[string]bool   // tagKeys of the stories marked read
+	hideRead  bool              // Whether read entries are hidden, toggled with X
+
+	priorityFilter string // When set, only entries of this priority level are shown, cycled with Tab
+	minPriority    string // When set, only entries of at least this priority are shown, cycled with m
+	sortByPriority bool   // Whether higher priorities are listed first, toggled with h
+
+	tooSmall atomic.Bool // Set while the terminal is below the minimum size
+
+	lastEntryAt     time.Time // When the last non-notice entry arrived
+	scannedAtLatest int64     // stats.scanned when the last entry arrived
+
+	sticky   HighValueInsight // Insight in the sticky headline, if stickyAt is set
+	stickyAt time.Time        // When the sticky insight arrived
+
+	escalation *escalation
+
+	logVisible bool // Whether the log pane is shown; only used on the event loop
+
+	compact   bool            // Whether summaries and URLs are hidden, toggled with S
+	hideURLs  bool            // Whether URL lines are hidden, from -hide-urls and toggled with u
+	grouped   bool            // Whether the feed is grouped by source, toggled with G
+	collapsed map[string]bool // Sections of the grouped view that are collapsed
+}
+
+// Runs the interactive tview feed until the user quits
+func runTUI(ctx context.Context, cfg Config, seen *seenSet) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	t := &tui{
+		ctx:       ctx,
+		cfg:       cfg,
+		app:       tview.NewApplication(),
+		seen:      seen,
+		selected:  -1,
+		tags:      make(map[string]string),
+		read:      make(map[string]bool),
+		collapsed: make(map[string]bool),
+		refresh:   make(chan struct{}, 1),
+		hideURLs:  cfg.HideURLs,
+
+		lastEntryAt: time.Now(),
+		escalation:  newEscalation(cfg.EscalateCount, cfg.EscalateWindow),
+	}
+
+	// Create a TextView for the scrolling feed
+	t.feedView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true).
+		SetScrollable(true).
+		SetWrap(true).
+		SetChangedFunc(func() {
+			t.app.Draw()
+		})
+
+	t.feedView.SetBorder(true).SetTitle(feedTitle(cfg))
+	t.feedView.SetInputCapture(t.handleKey)
+	t.setTheme(cfg.Theme)
+
+	// One-line status bar below the feed with short-lived messages on the
+	// left and running counters on the right
+	t.statusView = tview.NewTextView().SetDynamicColors(true)
+	t.statsView = tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignRight)
+	statusBar := tview.NewFlex().
+		AddItem(t.statusView, 0, 1, false).
+		AddItem(t.statsView, 0, 1, false)
+
+	// Sticky headline and incident banner above the feed, hidden until needed
+	t.stickyView = tview.NewTextView().SetDynamicColors(true)
+	t.alertView = tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+	t.alertView.SetBackgroundColor(tcell.ColorDarkRed)
+
+	// Diagnostics pane below the feed, hidden until toggled
+	t.logView = tview.NewTextView().SetScrollable(true).SetWrap(true)
+	t.logView.SetBorder(true).SetTitle("Log")
+
+	t.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(t.alertView, 0, 0, false).
+		AddItem(t.stickyView, 0, 0, false).
+		AddItem(t.feedView, 0, 1, true).
+		AddItem(t.logView, 0, 0, false).
+		AddItem(statusBar, 1, 0, false)
+
+	// Overlays such as the help view are stacked on top of the feed
+	t.pages = tview.NewPages().AddPage("feed", t.layout, true, true)
+	t.registerKeys()
+
+	// Keep the counters current
+	go func() {
+		for range time.Tick(time.Second) {
+			t.app.QueueUpdateDraw(func() {
+				t.statsView.SetText(sourceHealth.String() + stats.String())
+				t.expireSticky()
+				t.expireEntries()
+				if cfg.TimeBasedFade > 0 || cfg.MinDisplay > 0 {
+					t.render() // Entries age even when nothing arrives
+				}
+				t.updateAlert()
+				if t.logVisible {
+					t.logView.SetText(recentLogs.String()).ScrollToEnd()
+				}
+			})
+		}
+	}()
+
+	if cfg.Heartbeat > 0 {
+		go func() {
+			for range time.Tick(cfg.Heartbeat) {
+				t.heartbeat()
+			}
+		}()
+	}
+
+	// Periodically fetch, analyze, and update the feed
+	if cfg.Warmup {
+		t.statusView.SetText("Warming up model…")
+	}
+	stopPolling := startPollFeed(ctx, cfg, seen, t.refresh, feedHandlers{
+		CycleStart: func() {
+			t.mu.Lock()
+			t.cycle++
+			first := t.cycle == 1
+			t.mu.Unlock()
+			if first && cfg.Warmup {
+				t.app.QueueUpdateDraw(func() { t.statusView.Clear() })
+			}
+		},
+		Insight: func(insight HighValueInsight, err error) {
+			if err != nil {
+				insight.Summary = "[red]Analysis not available[-]"
+			}
+			t.add(feedEntry{Insight: insight})
+		},
+		Error: func(err error) {
+			t.add(feedEntry{Err: err})
+		},
+		Repost: t.mergeRepost,
+		Skipped: func(story Story, reason string) {
+			t.add(feedEntry{
+				Insight:    HighValueInsight{ID: story.ID, Title: story.Title, URL: story.URL, DiscussionURL: story.DiscussionURL},
+				SkipReason: reason,
+			})
+		},
+	})
+
+	// Snapshot the feed on demand for incident handoff. Stderr is the
+	// terminal the feed is drawn on, so without -dump-file it goes to a file.
+	dumpPath := cfg.DumpFile
+	if dumpPath == "" {
+		dumpPath = filepath.Join(os.TempDir(), "intelstream-dump.json")
+	}
+	onDumpSignal(func() {
+		insights := t.insights()
+		if err := dumpInsights(insights, dumpPath); err != nil {
+			t.app.QueueUpdateDraw(func() {
+				t.flash(fmt.Sprintf("[red]Dump failed: %v[-]", err))
+			})
+			return
+		}
+		slog.Info("dumped insights", "count", len(insights), "path", dumpPath)
+		t.app.QueueUpdateDraw(func() {
+			t.flash(fmt.Sprintf("[green]Dumped %d insights to %s[-]", len(insights), dumpPath))
+		})
+	})
+
+	// Quit cleanly on SIGTERM so state is saved on shutdown
+	go func() {
+		<-ctx.Done()
+		t.app.Stop()
+	}()
+
+	// Replace the feed with a notice while the terminal is too small
+	t.app.SetBeforeDrawFunc(t.checkSize)
+
+	// Restore tags and view settings from the last session
+	if cfg.StateFile != "" {
+		state, err := loadUIState(cfg.StateFile)
+		if err != nil {
+			t.flash(fmt.Sprintf("[yellow]Ignoring state file: %v[-]", err))
+		} else {
+			t.restoreState(state)
+		}
+	}
+
+	// Set up and run the app
+	err := t.app.SetRoot(t.pages, true).EnableMouse(true).Run()
+	cancel() // Stops re-analyses too
+	stopPolling()
+	if err != nil {
+		return err
+	}
+
+	if cfg.StateFile != "" {
+		if err := saveUIState(cfg.StateFile, t.state()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save UI state: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// Expands the -feed-title and -feed-subtitle templates into the feed's title
+func feedTitle(cfg Config) string {
+	r := strings.NewReplacer(
+		"{model}", analysisModel,
+		"{sources}", strconv.Itoa(len(cfg.Sources)),
+		"{version}", version,
+	)
+
+	title := r.Replace(cfg.FeedTitle)
+	if cfg.FeedSubtitle != "" {
+		title += " — " + r.Replace(cfg.FeedSubtitle)
+	}
+	title += " (focus: " + cfg.Focus + ")"
+	return tview.Escape(title)
+}
+
+// Adds an entry to the top of the feed, keeping the selection on the same entry
+func (t *tui) add(entry feedEntry) {
+	var sticky string
+	var escalated bool
+	t.mu.Lock()
+	entry.AddedAt = time.Now()
+	if entry.Notice == "" {
+		t.lastEntryAt = time.Now()
+		t.scannedAtLatest = stats.scanned.Load()
+	}
+	if entry.Err != nil && len(t.entries) > 0 && t.entries[0].Err != nil &&
+		t.entries[0].Err.Error() == entry.Err.Error() {
+		// Count a recurring error on the existing entry instead of repeating it
+		t.entries[0].Repeats++
+		t.entries[0].AddedAt = entry.AddedAt
+	} else {
+		// Stories keep their incident tag when they reappear
+		if entry.isStory() {
+			entry.Insight.Tag = t.tags[tagKey(entry.Insight)]
+			entry.Read = t.read[tagKey(entry.Insight)]
+			entry.Cycle = t.cycle
+			sticky = t.offerSticky(entry.Insight)
+			escalated = priorityLevel(entry.Insight.Priority) == priorityHigh && t.escalation.record(time.Now())
+		}
+		if i := t.placeholderFor(entry); i >= 0 {
+			// An analysis held back by a pause replaces its headline in place
+			entry.Cycle = 0
+			entry.AddedAt = t.entries[i].AddedAt
+			t.entries[i] = entry
+		} else if !t.groupLow(entry) {
+			t.entries = addEntry(t.entries, entry, t.cfg.MaxEntries)
+			if t.selected >= 0 {
+				t.selected = min(t.selected+1, len(t.entries)-1)
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	// Waiting on the event loop with t.mu held would deadlock it
+	if sticky != "" {
+		t.showSticky(sticky)
+	}
+	if escalated {
+		t.app.QueueUpdateDraw(t.updateAlert)
+	}
+	t.render()
+}
+
+// Returns the index of the unanalyzed headline entry for the same story as
+// entry, or -1 if there is none. The caller must hold t.mu.
+func (t *tui) placeholderFor(entry feedEntry) int {
+	if !entry.isStory() || entry.Insight.Unanalyzed {
+		return -1
+	}
+	return slices.IndexFunc(t.entries, func(e feedEntry) bool {
+		return e.isStory() && e.Insight.Unanalyzed && e.Insight.ID == entry.Insight.ID
+	})
+}
+
+// Folds a Low-priority entry into a summary of this cycle's Low entries once
+// -group-low of them have arrived in a row. Returns true if the entry was
+// grouped. The caller must hold t.mu.
+func (t *tui) groupLow(entry feedEntry) bool {
+	if t.cfg.GroupLow < 2 || !isGroupableLow(entry, t.cycle) {
+		return false
+	}
+
+	// Extend this cycle's existing group
+	if len(t.entries) > 0 && t.entries[0].Group != nil && t.entries[0].Cycle == t.cycle {
+		t.entries[0].Group = append([]feedEntry{entry}, t.entries[0].Group...)
+		t.entries[0].AddedAt = entry.AddedAt
+		return true
+	}
+
+	// Count the run of Low entries from this cycle at the top of the feed
+	run := 0
+	for run < len(t.entries) && t.entries[run].Group == nil && isGroupableLow(t.entries[run], t.cycle) {
+		run++
+	}
+	if run+1 < t.cfg.GroupLow {
+		return false
+	}
+
+	group := feedEntry{Cycle: t.cycle, Group: append([]feedEntry{entry}, t.entries[:run]...), AddedAt: entry.AddedAt}
+	t.entries = append([]feedEntry{group}, t.entries[run:]...)
+	if t.selected >= run {
+		t.selected -= run - 1
+	} else if t.selected >= 0 {
+		t.selected = 0
+	}
+	return true
+}
+
+// Reports whether an entry is a Low-priority story from the given cycle
+func isGroupableLow(entry feedEntry, cycle int) bool {
+	return entry.isStory() && entry.Cycle == cycle && priorityLevel(entry.Insight.Priority) == priorityLow
+}
+
+// Replaces the selected summary entry with the entries it collapsed
+func (t *tui) expandSelected() {
+	t.mu.Lock()
+	if t.selected < 0 || t.selected >= len(t.entries) || t.entries[t.selected].Group == nil {
+		t.mu.Unlock()
+		t.flash("[yellow]Select a collapsed group to expand[-]")
+		return
+	}
+
+	// Expanded entries stay individual for the rest of their cycle
+	group := t.entries[t.selected].Group
+	for i := range group {
+		group[i].Cycle = 0
+	}
+	t.entries = slices.Replace(t.entries, t.selected, t.selected+1, group...)
+	t.mu.Unlock()
+
+	t.render()
+}
+
+// Removes the entry for the given story, moving the selection to its neighbor
+func (t *tui) removeStory(id storyID) {
+	t.mu.Lock()
+	i := slices.IndexFunc(t.entries, func(entry feedEntry) bool {
+		return entry.isStory() && entry.Insight.ID == id
+	})
+	if i >= 0 {
+		t.entries = slices.Delete(t.entries, i, i+1)
+		if i < t.selected || t.selected >= len(t.entries) {
+			t.selected--
+		}
+		t.selectVisible()
+	}
+	t.mu.Unlock()
+
+	t.render()
+}
+
+// Removes entries that joined the feed more than -entry-ttl ago, keeping the
+// selection on the same entry where it survives. Runs on the event loop.
+func (t *tui) expireEntries() {
+	if t.cfg.EntryTTL == 0 {
+		return
+	}
+	t.mu.Lock()
+	var selectedEntry *feedEntry
+	if t.selected >= 0 {
+		selectedEntry = &t.entries[t.selected]
+	}
+	kept := t.entries[:0:0]
+	newSelected := -1
+	for i := range t.entries {
+		if time.Since(t.entries[i].AddedAt) >= t.cfg.EntryTTL {
+			continue
+		}
+		if &t.entries[i] == selectedEntry {
+			newSelected = len(kept)
+		}
+		kept = append(kept, t.entries[i])
+	}
+	expired := len(kept) < len(t.entries)
+	if expired {
+		t.entries = kept
+		t.selected = newSelected
+		if selectedEntry != nil && newSelected < 0 && len(kept) > 0 {
+			t.selected = len(kept) - 1
+		}
+		t.selectVisible()
+	}
+	t.mu.Unlock()
+
+	if expired {
+		t.render()
+	}
+}
+
+// Redraws the feed text and selection highlight from the current entries
+func (t *tui) render() {
+	// Nothing useful can be shown; checkSize renders again once it's resized
+	if t.tooSmall.Load() {
+		return
+	}
+
+	t.mu.Lock()
+	th := themes[t.theme]
+	var text string
+	if t.isGrouped() {
+		text = t.groupedText(th)
+	} else {
+		visible := t.visibleIndices()
+		var messages []string
+		for _, i := range visible {
+			// Wrap each entry in a region so it can be highlighted when selected
+			messages = append(messages, fmt.Sprintf(`["%d"]%s[""]`, i, formatEntry(t.entries[i], th, t.cfg, t.compact, t.hideURLs)))
+		}
+		text = t.fadeEntries(visible, messages, th)
+	}
+	selected := t.selected
+	title := t.feedTitle()
+	t.mu.Unlock()
+
+	t.feedView.SetTitle(title)
+	t.feedView.SetText(text)
+	if selected >= 0 {
+		t.feedView.Highlight(strconv.Itoa(selected)).ScrollToHighlight()
+	} else {
+		t.feedView.Highlight()
+	}
+}
+
+// Fades the formatted entries at the given indices from newest to oldest, by
+// position or, with -time-based-fade, by how long they've been in the feed.
+// Entries newer than -min-display stay at full brightness either way. The
+// caller must hold t.mu.
+func (t *tui) fadeEntries(indices []int, messages []string, th theme) string {
+	levels := th.fade(t.cfg)
+	if t.cfg.TimeBasedFade == 0 && t.cfg.MinDisplay == 0 && !t.sortByPriority {
+		return formatEntriesWithFade(messages, levels)
+	}
+	// Positional fades follow each entry's recency among those shown, which
+	// is its position unless the feed is sorted by priority
+	recency := make([]int, len(indices))
+	byAge := slices.Clone(indices)
+	slices.Sort(byAge)
+	for n, i := range indices {
+		recency[n], _ = slices.BinarySearch(byAge, i)
+	}
+	if t.cfg.TimeBasedFade == 0 && t.cfg.MinDisplay == 0 {
+		return writeFaded(messages, levels, func(n int) int {
+			return recency[n] * (len(levels) - 1) / len(messages)
+		})
+	}
+	ages := make([]time.Duration, len(indices))
+	for n, i := range indices {
+		ages[n] = time.Since(t.entries[i].AddedAt)
+	}
+
+	if t.cfg.TimeBasedFade > 0 {
+		// The fade starts once the entry has been shown for -min-display
+		for n := range ages {
+			ages[n] = max(0, ages[n]-t.cfg.MinDisplay)
+		}
+		return formatEntriesWithAgeFade(messages, ages, levels, t.cfg.TimeBasedFade)
+	}
+	return writeFaded(messages, levels, func(n int) int {
+		if ages[n] < t.cfg.MinDisplay {
+			return 0
+		}
+		return recency[n] * (len(levels) - 1) / len(messages)
+	})
+}
+
+// Before each draw, shows a "terminal too small" notice instead of the feed
+// when the screen is below the minimum size. Returns true to skip drawing.
+func (t *tui) checkSize(screen tcell.Screen) bool {
+	width, height := screen.Size()
+	if width < minTermWidth || height < minTermHeight {
+		t.tooSmall.Store(true)
+		size := fmt.Sprintf("%dx%d, need %dx%d", width, height, minTermWidth, minTermHeight)
+		tview.Print(screen, "Terminal too small", 0, height/2-1, width, tview.AlignCenter, tcell.ColorYellow)
+		tview.Print(screen, size, 0, height/2, width, tview.AlignCenter, tcell.ColorYellow)
+		return true
+	}
+
+	// Catch up on updates skipped while the terminal was too small
+	if t.tooSmall.Swap(false) {
+		go t.render()
+	}
+	return false
+}
+
+// Switches to the named theme and redraws the feed
+func (t *tui) setTheme(name string) {
+	t.mu.Lock()
+	t.theme = name
+	t.mu.Unlock()
+
+	th := themes[name]
+	t.feedView.SetBorderColor(th.Border).SetTitleColor(th.Border)
+	t.render()
+}
+
+// Switches to the next built-in theme
+func (t *tui) cycleTheme() {
+	t.mu.Lock()
+	next := themeNames[(slices.Index(themeNames, t.theme)+1)%len(themeNames)]
+	t.mu.Unlock()
+
+	t.setTheme(next)
+	t.flash("Theme: " + next)
+}
+
+// Returns a copy of the analyzed insights currently in the feed, newest first
+func (t *tui) insights() []HighValueInsight {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var insights []HighValueInsight
+	for _, entry := range t.entries {
+		if entry.isStory() || entry.Insight.Digest {
+			insights = append(insights, entry.Insight)
+		}
+		for _, member := range entry.Group {
+			insights = append(insights, member.Insight)
+		}
+	}
+	return insights
+}
+
+// Returns the selected entry, if any
+func (t *tui) selectedEntry() (feedEntry, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.selected < 0 || t.selected >= len(t.entries) {
+		return feedEntry{}, false
+	}
+	return t.entries[t.selected], true
+}
+
+// Moves the selection by delta visible entries, clamped to the feed
+func (t *tui) moveSelection(delta int) {
+	t.mu.Lock()
+	visible := t.visibleIndices()
+	if len(visible) > 0 {
+		pos := slices.Index(visible, t.selected)
+		if pos < 0 {
+			pos = 0
+		} else {
+			pos = max(0, min(pos+delta, len(visible)-1))
+		}
+		t.selected = visible[pos]
+	}
+	t.mu.Unlock()
+
+	t.render()
+}
+
+// Returns the indices of the entries shown in the feed, in display order:
+// newest first, or section by section in the grouped view. The caller must
+// hold t.mu.
+func (t *tui) visibleIndices() []int {
+	visible := t.filteredIndices()
+	if t.isGrouped() {
+		return t.sectionOrder(visible)
+	}
+	return visible
+}
+
+// Returns the indices of the entries that pass the active filters, newest
+// first or, with h, highest priority first. The caller must hold t.mu.
+func (t *tui) filteredIndices() []int {
+	var visible []int
+	for i, entry := range t.entries {
+		if t.tagFilter != "" && (!entry.isStory() || entry.Insight.Tag != t.tagFilter) {
+			continue
+		}
+		if !t.passesPriorityFilter(entry) || (t.hideRead && entry.Read) {
+			continue
+		}
+		visible = append(visible, i)
+	}
+	if t.sortByPriority {
+		// Stable, so each priority stays newest first
+		slices.SortStableFunc(visible, func(a, b int) int {
+			return priorityRank(t.entries[b].Insight.Priority) - priorityRank(t.entries[a].Insight.Priority)
+		})
+	}
+	return visible
+}
+
+// Moves the selection to the first visible entry if the selected one was
+// filtered out. The caller must hold t.mu.
+func (t *tui) selectVisible() {
+	if t.selected < 0 {
+		return
+	}
+	visible := t.visibleIndices()
+	if slices.Contains(visible, t.selected) {
+		return
+	}
+	t.selected = -1
+	if len(visible) > 0 {
+		t.selected = visible[0]
+	}
+}
+
+// Shows a message in the status bar for a few seconds
+func (t *tui) flash(message string) {
+	t.statusView.SetText(message)
+	time.AfterFunc(3*time.Second, func() {
+		t.app.QueueUpdateDraw(func() {
+			if t.statusView.GetText(false) == message {
+				t.statusView.Clear()
+			}
+		})
+	})
+}
+
+// Asks the poll loop to run a cycle now instead of waiting for the next tick.
+// Presses while a refresh is already pending are dropped, so cycles never
+// overlap or pile up.
+func (t *tui) refreshNow() {
+	select {
+	case t.refresh <- struct{}{}:
+		t.flash("Refreshing…")
+	default:
+		t.flash("[yellow]Refresh already pending[-]")
+	}
+}
+
+// Shows or hides the log pane below the feed
+func (t *tui) toggleLog() {
+	t.logVisible = !t.logVisible
+	if t.logVisible {
+		t.logView.SetText(recentLogs.String()).ScrollToEnd()
+		t.layout.ResizeItem(t.logView, logPaneHeight, 0)
+	} else {
+		t.layout.ResizeItem(t.logView, 0, 0)
+	}
+}
+
+// Copies the selected entry's URL to the system clipboard
+func (t *tui) copySelectedURL() {
+	entry, ok := t.selectedEntry()
+	if !ok {
+		t.flash("[yellow]No entry selected[-]")
+		return
+	}
+	if entry.Insight.URL == "" {
+		t.flash("[yellow]Selected entry has no URL[-]")
+		return
+	}
+
+	if err := copyToClipboard(entry.Insight.URL); err != nil {
+		t.flash(fmt.Sprintf("[red]Copy failed: %v[-]", err))
+		return
+	}
+	t.flash("[green]Copied URL to clipboard[-]")
+}
+
+// Switches between full entries and compact one-line entries
+func (t *tui) toggleSummaries() {
+	t.mu.Lock()
+	t.compact = !t.compact
+	t.mu.Unlock()
+
+	t.render()
+}
+
+// Shows or hides the URL line of full entries; the detail view and o keep
+// the URL either way
+func (t *tui) toggleURLs() {
+	t.mu.Lock()
+	t.hideURLs = !t.hideURLs
+	t.mu.Unlock()
+
+	t.render()
+}
+
+// Opens the selected entry's article, or with discussion set its comments
+// page, in the browser
+func (t *tui) openSelected(discussion bool) {
+	entry, ok := t.selectedEntry()
+	if !ok {
+		t.flash("[yellow]No entry selected[-]")
+		return
+	}
+	url := entry.Insight.URL
+	if discussion {
+		url = entry.Insight.DiscussionURL
+	}
+	if url == "" {
+		t.flash("[yellow]Selected entry has no such link[-]")
+		return
+	}
+
+	if err := openInBrowser(url); err != nil {
+		t.flash(fmt.Sprintf("[red]Open failed: %v[-]", err))
+		return
+	}
+	t.flash("[green]Opened in browser[-]")
+}
+
+// Shows a one-line input prompt over the feed, calling done with the entered
+// text when the user presses Enter. Esc cancels.
+func (t *tui) prompt(label, initial string, done func(text string)) {
+	input := tview.NewInputField().
+		SetLabel(label).
+		SetText(initial)
+	input.SetBorder(true)
+	input.SetDoneFunc(func(key tcell.Key) {
+		t.pages.RemovePage("prompt")
+		if key == tcell.KeyEnter {
+			done(input.GetText())
+		}
+	})
+
+	t.pages.AddPage("prompt", centered(input, 50, 3), true, true)
+}
+
+// Shows a yes/no question over the feed and calls yes if it's confirmed
+func (t *tui) confirm(question string, yes func()) {
+	modal := tview.NewModal().
+		SetText(question).
+		AddButtons([]string{"Cancel", "Yes"}).
+		SetDoneFunc(func(_ int, label string) {
+			t.pages.RemovePage("confirm")
+			if label == "Yes" {
+				yes()
+			}
+		})
+	t.pages.AddPage("confirm", modal, true, true)
+}
+
+// Empties the feed after confirmation. Seen stories stay seen, so cleared
+// entries don't come straight back.
+func (t *tui) clearFeed() {
+	t.confirm("Clear every entry from the feed?", func() {
+		t.mu.Lock()
+		t.entries = nil
+		t.selected = -1
+		t.mu.Unlock()
+
+		t.render()
+		t.flash("Feed cleared")
+	})
+}
+
+// Centers a fixed-size box over whatever is below it
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+}
+
+// Adds a new entry to the top of the list and keeps the most recent limit entries
+func addEntry(entries []feedEntry, entry feedEntry, limit int) []feedEntry {
+	// Add the new entry to the top of the list
+	entries = append([]feedEntry{entry}, entries...)
+
+	// If the list exceeds the maximum number of entries, remove the oldest one
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries
+}
+
+// Formats a single entry with the theme's color tags for the feed, with the
+// title shortened to -title-width runes. Compact entries are a single line of
+// priority, domain and title.
+func formatEntry(entry feedEntry, th theme, cfg Config, compact, hideURLs bool) string {
+	titleWidth := cfg.TitleWidth
+	if entry.Group != nil {
+		return fmt.Sprintf("%s%d Low-priority items — press x to expand[-:-:-]", th.Priority, len(entry.Group))
+	}
+	if entry.Notice != "" {
+		return entry.Notice // Uncolored so it fades like the entries around it
+	}
+	if entry.SkipReason != "" {
+		return fmt.Sprintf("[gray::d]Skipped (%s): %s[-::-]", entry.SkipReason, truncateText(entry.Insight.Title, titleWidth))
+	}
+	if entry.Err != nil {
+		if entry.Repeats > 0 {
+			return fmt.Sprintf("[red]Error: %v (x%d)[-]", entry.Err, entry.Repeats+1)
+		}
+		return fmt.Sprintf("[red]Error: %v[-]", entry.Err)
+	}
+	insight := entry.Insight
+	tag := ""
+	if insight.Tag != "" {
+		tag = fmt.Sprintf(" [fuchsia]#%s[-]", tview.Escape(insight.Tag))
+	}
+	if entry.Reposts > 0 {
+		tag += fmt.Sprintf(" [gray](reposted x%d)[-]", entry.Reposts)
+	}
+	if entry.Reanalyzing {
+		tag += " [gray](re-analyzing…)[-]"
+	} else if cfg.ShowLatency && insight.AnalysisDuration > 0 {
+		tag += fmt.Sprintf(" [gray::d]%v[-::-]", insight.AnalysisDuration.Round(100*time.Millisecond))
+	}
+	titleColor := th.Title
+	if entry.Read {
+		titleColor = "[gray::d]"
+		tag += " [gray]✓[-]"
+	}
+	source := ""
+	if cfg.ShowDomain || compact || hideURLs {
+		if domain := domainOf(insight.URL); domain != "" {
+			source = fmt.Sprintf("[%s]%s[-] ", domainColor(domain), tview.Escape("["+domain+"]"))
+		}
+	}
+	summary := insight.Summary
+	if insight.RepeatedSummary {
+		summary = "[gray::d](same summary as a recent story)[-::-]"
+	}
+	if compact {
+		// The bare level keeps the line short; boost notes and the like
+		// stay in the detail view
+		priority := priorityLevel(insight.Priority)
+		if priority == "" {
+			priority = insight.Priority
+		}
+		return fmt.Sprintf("%s%s[-:-:-] %s%s%s[-:-:-]%s",
+			th.Priority, priority, source, titleColor, truncateText(insight.Title, titleWidth), tag)
+	}
+	if hideURLs {
+		return fmt.Sprintf("%sPriority: %s[-:-:-]%s\n%s%s%s[-:-:-]\n%s",
+			th.Priority, insight.Priority, tag, source, titleColor, truncateText(insight.Title, titleWidth), summary)
+	}
+	return fmt.Sprintf("%sPriority: %s[-:-:-]%s\n%s%s%s[-:-:-]\n%s\n%s",
+		th.Priority, insight.Priority, tag, source, titleColor, truncateText(insight.Title, titleWidth), insight.URL, summary)
+}
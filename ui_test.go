@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestAddEntry(t *testing.T) {
+	// Adds entries titled "1" to "n" in order and returns the feed's titles
+	fill := func(n, limit int) []string {
+		var entries []feedEntry
+		for i := 1; i <= n; i++ {
+			entries = addEntry(entries, feedEntry{Insight: HighValueInsight{Title: fmt.Sprint(i)}}, limit)
+		}
+		var titles []string
+		for _, entry := range entries {
+			titles = append(titles, entry.Insight.Title)
+		}
+		return titles
+	}
+
+	tests := []struct {
+		name  string
+		added int
+		limit int
+		want  []string
+	}{
+		{"empty", 0, 3, nil},
+		{"under the cap", 2, 3, []string{"2", "1"}},
+		{"exactly at the cap", 3, 3, []string{"3", "2", "1"}},
+		{"one over the cap", 4, 3, []string{"4", "3", "2"}},
+		{"well over the cap", 10, 3, []string{"10", "9", "8"}},
+		{"cap of one", 3, 1, []string{"3"}},
+		{"default -max-entries", 25, 20, []string{"25", "24", "23", "22", "21", "20", "19", "18", "17", "16", "15", "14", "13", "12", "11", "10", "9", "8", "7", "6"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fill(tt.added, tt.limit); !slices.Equal(got, tt.want) {
+				t.Errorf("after adding %d with limit %d, feed = %v, want %v", tt.added, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsStory(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry feedEntry
+		want  bool
+	}{
+		{"story", feedEntry{Insight: HighValueInsight{ID: hnID(1)}}, true},
+		{"digest", feedEntry{Insight: HighValueInsight{Title: "Digest of 5 stories", Digest: true}}, false},
+		{"error", feedEntry{Err: errEmptyResponse}, false},
+		{"notice", feedEntry{Notice: "Still running"}, false},
+		{"skipped", feedEntry{SkipReason: "matches -exclude-regex"}, false},
+		{"group", feedEntry{Group: []feedEntry{{}}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.isStory(); got != tt.want {
+			t.Errorf("%s: isStory() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
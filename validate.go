@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Prints every flag's effective value and where it came from: set on the
+// command line, taken from the environment, or left at its default
+func printEffectiveConfig(w io.Writer) error {
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	flag.VisitAll(func(f *flag.Flag) {
+		source := "default"
+		switch {
+		case set[f.Name]:
+			source = "flag"
+		case f.Name == "ollama-url" && os.Getenv("OLLAMA_HOST") != "":
+			source = "env OLLAMA_HOST"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t(%s)\n", f.Name, redactURLs(f.Value.String()), source)
+	})
+	return tw.Flush()
+}
+
+// Redacts each of the space-separated URLs a repeatable flag prints as
+func redactURLs(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return redactURL(value)
+	}
+	for i, field := range fields {
+		fields[i] = redactURL(field)
+	}
+	return strings.Join(fields, " ")
+}
+
+// Replaces the password in a URL with "xxxxx" so credentials embedded in
+// -ollama-url and similar values aren't printed
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, ok := u.User.Password(); !ok {
+		return value
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	webhookQueueSize = 100              // Insights waiting to be delivered before new ones are dropped
+	webhookTimeout   = 10 * time.Second // Limit on each delivery attempt
+	webhookRetryBase = time.Second      // Wait before the first retry, doubled for each one after
+	webhookDrainTime = 30 * time.Second // Longest Close waits for queued deliveries
+)
+
+// POSTs each insight at or above a priority to a URL, as its JSON record or
+// a body rendered from a template. Deliveries run in the background so a
+// slow or failing endpoint never holds up the feed; Emit only queues.
+type webhookSink struct {
+	url         string
+	headers     http.Header
+	minPriority string             // Lowest priority delivered, "" for all
+	tmpl        *template.Template // Renders the body from the insight; nil sends the JSON record
+	retries     int
+	queue       chan HighValueInsight
+	done        chan struct{} // Closed once run has delivered everything queued
+
+	mu     sync.Mutex // Guards queue against sends after Close
+	closed bool
+}
+
+func newWebhookSink(url string, headers http.Header, minPriority string, tmpl *template.Template, retries int) *webhookSink {
+	s := &webhookSink{
+		url:         url,
+		headers:     headers,
+		minPriority: minPriority,
+		tmpl:        tmpl,
+		retries:     retries,
+		queue:       make(chan HighValueInsight, webhookQueueSize),
+		done:        make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *webhookSink) Emit(insight HighValueInsight) error {
+	if s.minPriority != "" && priorityRank(insight.Priority) < priorityRank(s.minPriority) {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return fmt.Errorf("sink closed, dropping story %s", insight.ID)
+	}
+	select {
+	case s.queue <- insight:
+		return nil
+	default:
+		return fmt.Errorf("delivery queue full, dropping story %s", insight.ID)
+	}
+}
+
+// Delivers queued insights one at a time, in the order they were emitted
+func (s *webhookSink) run() {
+	defer close(s.done)
+	for insight := range s.queue {
+		body, err := s.body(insight)
+		if err != nil {
+			slog.Error("webhook body failed", "id", insight.ID, "err", err)
+			continue
+		}
+		if err := s.deliver(body); err != nil {
+			slog.Error("webhook delivery failed", "id", insight.ID, "err", err)
+		}
+	}
+}
+
+// Stops accepting insights and waits a while for the queued ones to be
+// delivered, so a one-shot run doesn't exit before its alerts go out. Emit
+// calls after Close fail, since a poller that didn't stop in time may still
+// be running.
+func (s *webhookSink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	select {
+	case <-s.done:
+		return nil
+	case <-time.After(webhookDrainTime):
+		return fmt.Errorf("gave up waiting for %d queued deliveries", len(s.queue))
+	}
+}
+
+func (s *webhookSink) body(insight HighValueInsight) ([]byte, error) {
+	if s.tmpl == nil {
+		return json.Marshal(newInsightRecord(insight))
+	}
+	var buf bytes.Buffer
+	if err := s.tmpl.Execute(&buf, insight); err != nil {
+		slog.Warn("webhook template failed, sending the JSON record instead", "id", insight.ID, "err", err)
+		return json.Marshal(newInsightRecord(insight))
+	}
+	return buf.Bytes(), nil
+}
+
+// POSTs body, retrying network errors, 429s and 5xx responses with a
+// doubling wait
+func (s *webhookSink) deliver(body []byte) error {
+	var err error
+	for attempt := 0; attempt <= s.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(webhookRetryBase << (attempt - 1))
+		}
+		var retry bool
+		retry, err = s.post(body)
+		if err == nil || !retry {
+			return err
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", s.retries+1, err)
+}
+
+// Makes one delivery attempt, reporting whether a failure is worth retrying
+func (s *webhookSink) post(body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range s.headers {
+		req.Header[name] = values
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		// The error quotes the URL, which often holds a token
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return true, fmt.Errorf("posting to %s: %v", webhookLabel(s.url), err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Lets the connection be reused
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("%s responded %s", webhookLabel(s.url), resp.Status)
+}
+
+// Returns the webhook URL for logs: its scheme and host only, since
+// services such as Slack put the secret in the path
+func webhookLabel(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "webhook"
+	}
+	label := u.Scheme + "://" + u.Host
+	if u.Path != "" && u.Path != "/" || u.RawQuery != "" {
+		label += "/…"
+	}
+	return label
+}
+
+// Starts the -webhook sink its flags describe
+func openWebhook(cfg Config) (*webhookSink, error) {
+	flags, err := parseWebhookFlags(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newWebhookSink(cfg.Webhook, flags.headers, flags.minPriority, flags.tmpl, cfg.WebhookRetries), nil
+}
+
+// The -webhook settings that need parsing
+type webhookFlags struct {
+	headers     http.Header
+	minPriority string
+	tmpl        *template.Template
+}
+
+// Checks the -webhook flags and parses the ones that need it. Header values
+// are kept out of errors, as with -header.
+func parseWebhookFlags(cfg Config) (webhookFlags, error) {
+	if u, err := url.Parse(cfg.Webhook); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return webhookFlags{}, fmt.Errorf("-webhook must be an http or https URL")
+	}
+	if cfg.WebhookRetries < 0 {
+		return webhookFlags{}, fmt.Errorf("-webhook-retries must not be negative, got %d", cfg.WebhookRetries)
+	}
+
+	minPriority := ""
+	if cfg.WebhookMinPriority != "" {
+		minPriority = priorityLevel(cfg.WebhookMinPriority)
+		if !strings.EqualFold(minPriority, cfg.WebhookMinPriority) {
+			return webhookFlags{}, fmt.Errorf("-webhook-min-priority must be High, Medium or Low, got %q", cfg.WebhookMinPriority)
+		}
+	}
+
+	headers := http.Header{}
+	for i, spec := range cfg.WebhookHeaderSpecs {
+		name, value, err := parseHeader(spec)
+		if err != nil {
+			return webhookFlags{}, fmt.Errorf("-webhook-header #%d: %v", i+1, err)
+		}
+		headers.Add(name, value)
+	}
+
+	var tmpl *template.Template
+	if cfg.WebhookTemplate != "" {
+		var err error
+		if tmpl, err = loadWebhookTemplate(cfg.WebhookTemplate); err != nil {
+			return webhookFlags{}, fmt.Errorf("-webhook-template: %v", err)
+		}
+	}
+	return webhookFlags{headers: headers, minPriority: minPriority, tmpl: tmpl}, nil
+}
+
+// Parses a -webhook-template file. Templates see the insight's fields, such
+// as {{.Title}} and {{.Priority}}, and the json function quotes a value as a
+// JSON string.
+func loadWebhookTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(path).Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	// Catch references to fields insights don't have before the first delivery
+	if err := tmpl.Execute(io.Discard, HighValueInsight{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookDeliversAndRefusesAfterClose(t *testing.T) {
+	bodies := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer srv.Close()
+
+	s := newWebhookSink(srv.URL+"/hooks/secret", http.Header{}, priorityMedium, nil, 0)
+	for _, priority := range []string{priorityHigh, priorityLow} {
+		if err := s.Emit(HighValueInsight{ID: hnID(1), Title: priority + " story", Priority: priority}); err != nil {
+			t.Fatalf("Emit() error = %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// A poller that outlived shutdown must not panic the program
+	if err := s.Emit(HighValueInsight{ID: hnID(2), Priority: priorityHigh}); err == nil {
+		t.Error("Emit() after Close: error = nil, want an error")
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+
+	close(bodies)
+	var got []string
+	for body := range bodies {
+		got = append(got, body)
+	}
+	if len(got) != 1 || !strings.Contains(got[0], `"title":"High story"`) {
+		t.Errorf("delivered %q, want only the High story", got)
+	}
+}
+
+func TestWebhookErrorsHideTheURL(t *testing.T) {
+	s := newWebhookSink("http://127.0.0.1:1/services/T000/B000/secret-token?key=abc", http.Header{}, "", nil, 0)
+	defer s.Close()
+
+	_, err := s.post([]byte(`{}`))
+	if err == nil {
+		t.Fatal("post() error = nil, want a connection error")
+	}
+	if strings.Contains(err.Error(), "secret-token") || strings.Contains(err.Error(), "key=abc") {
+		t.Errorf("post() error = %q, want the URL's path and query left out", err)
+	}
+}